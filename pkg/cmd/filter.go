@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/primelib/primecodegen-app/pkg/failure"
+	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags adds the repository selection flags
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("repo", []string{}, "Only process the repositories, in the form namespace/name")
+	cmd.Flags().StringSlice("namespace", []string{}, "Only process repositories in the namespaces")
+	cmd.Flags().StringSlice("match", []string{}, "Only process repositories whose namespace/name matches a glob, e.g. primelib/*-java")
+	cmd.Flags().StringSlice("topic", []string{}, "Only process repositories with at least one of the topics")
+	cmd.Flags().String("since", "", "Only process repositories whose config or upstream spec changed since a duration (24h) or date (2025-01-31)")
+}
+
+// addStepTimeoutFlags adds the timeouts of the external processes
+func addStepTimeoutFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("fetch-timeout", primelib.DefaultFetchTimeout, "Timeout per spec source download, 0 disables the timeout")
+	cmd.Flags().Duration("convert-timeout", 5*time.Minute, "Timeout per spec conversion, 0 disables the timeout")
+	cmd.Flags().Duration("generator-timeout", 20*time.Minute, "Timeout per generator run, 0 disables the timeout")
+	cmd.Flags().Duration("diff-timeout", 5*time.Minute, "Timeout of the spec diff, 0 disables the timeout")
+}
+
+// stepTimeoutsFromFlags reads the timeouts of the external processes
+func stepTimeoutsFromFlags(cmd *cobra.Command) primelib.Timeouts {
+	var timeouts primelib.Timeouts
+	timeouts.Fetch, _ = cmd.Flags().GetDuration("fetch-timeout")
+	timeouts.Convert, _ = cmd.Flags().GetDuration("convert-timeout")
+	timeouts.Generator, _ = cmd.Flags().GetDuration("generator-timeout")
+	timeouts.Diff, _ = cmd.Flags().GetDuration("diff-timeout")
+	return timeouts
+}
+
+// addExecuteFlags adds the repository selection, worker pool, timeout and failure report flags
+func addExecuteFlags(cmd *cobra.Command) {
+	addFilterFlags(cmd)
+	addStepTimeoutFlags(cmd)
+	cmd.Flags().Int("concurrency", 1, "Number of repositories processed in parallel")
+	cmd.Flags().Duration("timeout", 0, "Timeout per repository and task, 0 disables the timeout")
+	cmd.Flags().StringSlice("failure-report", []string{string(failure.ModeNone)}, "Report failures to the repository as an issue, a commit status or none")
+}
+
+// executeOptionsFromFlags reads the repository selection, worker pool, timeout and failure report flags
+func executeOptionsFromFlags(cmd *cobra.Command) (tasks.ExecuteOptions, error) {
+	var opts tasks.ExecuteOptions
+	var err error
+	opts.Filter, err = filterFromFlags(cmd)
+	if err != nil {
+		return opts, err
+	}
+	opts.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+	opts.Timeout, _ = cmd.Flags().GetDuration("timeout")
+	opts.Steps = stepTimeoutsFromFlags(cmd)
+
+	failureReport, _ := cmd.Flags().GetStringSlice("failure-report")
+	modes, err := failure.ParseModes(failureReport)
+	if err != nil {
+		return opts, err
+	}
+	opts.Failures = failure.NewReporter(modes)
+
+	return opts, nil
+}
+
+// filterFromFlags reads the repository selection flags
+func filterFromFlags(cmd *cobra.Command) (tasks.Filter, error) {
+	var filter tasks.Filter
+	filter.Repositories, _ = cmd.Flags().GetStringSlice("repo")
+	filter.Namespaces, _ = cmd.Flags().GetStringSlice("namespace")
+	filter.Patterns, _ = cmd.Flags().GetStringSlice("match")
+	filter.Topics, _ = cmd.Flags().GetStringSlice("topic")
+
+	since, _ := cmd.Flags().GetString("since")
+	if since != "" {
+		t, err := parseSince(since, time.Now())
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = &t
+	}
+
+	return filter, nil
+}
+
+// parseSince parses a duration relative to now, a RFC 3339 timestamp or a date
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since value %q, expected a duration, date or RFC 3339 timestamp", value)
+}
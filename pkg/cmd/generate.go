@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
 	"github.com/primelib/primecodegen-app/pkg/config"
 	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/report"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
 	"github.com/primelib/primecodegen-app/pkg/tasks/codegeneration"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -20,23 +23,32 @@ func generateCmd() *cobra.Command {
 		Aliases: []string{"g"},
 		Run: func(cmd *cobra.Command, args []string) {
 			dir, _ := cmd.Flags().GetString("dir")
+			reportFile, _ := cmd.Flags().GetString("report")
 
+			rep := report.New("generate")
 			if dir == "" {
-				generateApp()
+				opts, err := executeOptionsFromFlags(cmd)
+				if err != nil {
+					log.Fatal().Err(err).Msg("invalid execution options")
+				}
+				opts.Report = rep
+				generateApp(cmd.Context(), opts, reportFile)
 			} else {
-				generateLocal(dir)
+				generateLocal(cmd.Context(), dir, stepTimeoutsFromFlags(cmd), rep, reportFile)
 			}
 		},
 	}
 	cmd.Flags().Bool("dry-run", false, "Perform a dry run without making any changes")
 	cmd.Flags().String("dir", "", "Directory of the project for local code generation")
+	cmd.Flags().String("report", "", "Write a JSON run report to the given file")
+	addExecuteFlags(cmd)
 
 	return cmd
 }
 
-func generateApp() {
+func generateApp(ctx context.Context, opts tasks.ExecuteOptions, reportFile string) {
 	// tasks
-	tasks := []taskcommon.Task{codegeneration.NewTask()}
+	taskList := []taskcommon.Task{codegeneration.NewTask(opts.Report, opts.Steps)}
 
 	// platform
 	platform, err := vcsapp.GetPlatformFromEnvironment()
@@ -45,13 +57,14 @@ func generateApp() {
 	}
 
 	// execute
-	err = vcsapp.ExecuteTasks(platform, tasks)
+	err = tasks.ExecuteTasks(ctx, platform, taskList, opts)
+	writeReport(opts.Report, reportFile)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to execute generate task")
 	}
 }
 
-func generateLocal(dir string) {
+func generateLocal(ctx context.Context, dir string, timeouts primelib.Timeouts, rep *report.Report, reportFile string) {
 	configPath := path.Join(dir, "primelib.yaml")
 	bytes, err := os.ReadFile(configPath)
 	if err != nil {
@@ -66,7 +79,11 @@ func generateLocal(dir string) {
 
 	// for each module
 	log.Info().Str("dir", dir).Str("config", configPath).Msg("running local generation")
-	genErr := primelib.Generate(dir, conf, api.Repository{})
+	results, genErr := primelib.Generate(ctx, dir, conf, api.Repository{}, primelib.GenerateOptions{Timeouts: timeouts})
+	entry := rep.Repository(dir, "generate")
+	entry.AddGenerators(dir, results)
+	entry.Finish(genErr)
+	writeReport(rep, reportFile)
 	if genErr != nil {
 		log.Fatal().Err(genErr).Msg("failed to generate code")
 	}
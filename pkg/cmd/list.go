@@ -8,6 +8,7 @@ import (
 	"github.com/cidverse/cidverseutils/core/clioutputwriter"
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +19,11 @@ func listCmd() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			format, _ := cmd.Flags().GetString("format")
 			columns, _ := cmd.Flags().GetStringSlice("columns")
+			filter, err := filterFromFlags(cmd)
+			if err != nil {
+				slog.Error("invalid repository filter", "err", err)
+				os.Exit(1)
+			}
 
 			// platform
 			platform, err := vcsapp.GetPlatformFromEnvironment()
@@ -31,6 +37,11 @@ func listCmd() *cobra.Command {
 				IncludeBranches:   false,
 				IncludeCommitHash: false,
 			})
+			if err != nil {
+				slog.Error("failed to list repositories", "err", err)
+				os.Exit(1)
+			}
+			repos, _ = tasks.SelectRepositories(cmd.Context(), platform, repos, filter)
 
 			// data
 			data := clioutputwriter.TabularData{
@@ -61,6 +72,7 @@ func listCmd() *cobra.Command {
 
 	cmd.Flags().StringP("format", "f", string(clioutputwriter.DefaultOutputFormat()), fmt.Sprintf("output format %s", clioutputwriter.SupportedOutputFormats()))
 	cmd.Flags().StringSliceP("columns", "c", []string{}, "columns to display")
+	addFilterFlags(cmd)
 
 	return cmd
 }
@@ -3,6 +3,8 @@ package cmd
 import (
 	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
 	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
+	"github.com/primelib/primecodegen-app/pkg/report"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
 	"github.com/primelib/primecodegen-app/pkg/tasks/createtag"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -13,8 +15,16 @@ func releaseCmd() *cobra.Command {
 		Use:     "release",
 		Aliases: []string{"r"},
 		Run: func(cmd *cobra.Command, args []string) {
+			reportFile, _ := cmd.Flags().GetString("report")
+			rep := report.New("release")
+			opts, err := executeOptionsFromFlags(cmd)
+			if err != nil {
+				log.Fatal().Err(err).Msg("invalid execution options")
+			}
+			opts.Report = rep
+
 			// tasks
-			tasks := []taskcommon.Task{createtag.NewTask()}
+			taskList := []taskcommon.Task{createtag.NewTask(rep)}
 
 			// platform
 			platform, err := vcsapp.GetPlatformFromEnvironment()
@@ -23,13 +33,16 @@ func releaseCmd() *cobra.Command {
 			}
 
 			// execute
-			err = vcsapp.ExecuteTasks(platform, tasks)
+			err = tasks.ExecuteTasks(cmd.Context(), platform, taskList, opts)
+			writeReport(rep, reportFile)
 			if err != nil {
 				log.Fatal().Err(err).Msg("failed to execute release task")
 			}
 		},
 	}
 	cmd.Flags().Bool("dry-run", false, "Perform a dry run without making any changes")
+	cmd.Flags().String("report", "", "Write a JSON run report to the given file")
+	addExecuteFlags(cmd)
 
 	return cmd
 }
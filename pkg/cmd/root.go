@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/cidverse/cidverseutils/zerologconfig"
+	"github.com/primelib/primecodegen-app/pkg/tracing"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +19,7 @@ var (
 		LogFormat string
 		LogCaller bool
 	}{}
+	shutdownTracing = func(context.Context) error { return nil }
 )
 
 func rootCmd() *cobra.Command {
@@ -22,6 +28,18 @@ func rootCmd() *cobra.Command {
 		Short: `primelib-app is a application to automate code-generation for the primelib organization`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			zerologconfig.Configure(cfg)
+
+			shutdown, err := tracing.Setup(context.Background(), Version)
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to configure tracing, spans will not be exported")
+				return
+			}
+			shutdownTracing = shutdown
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if err := shutdownTracing(context.Background()); err != nil {
+				log.Warn().Err(err).Msg("failed to flush traces")
+			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			_ = cmd.Help()
@@ -36,12 +54,26 @@ func rootCmd() *cobra.Command {
 	cmd.AddCommand(generateCmd())
 	cmd.AddCommand(releaseCmd())
 	cmd.AddCommand(listCmd())
+	cmd.AddCommand(statusCmd())
+	cmd.AddCommand(serveCmd())
 	cmd.AddCommand(versionCmd())
 
 	return cmd
 }
 
-// Execute executes the root command.
+// Execute executes the root command, SIGINT and SIGTERM cancel the context of the command.
 func Execute() error {
-	return rootCmd().Execute()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		// restore the default behavior, so a second signal terminates immediately
+		signal.Stop(signals)
+		log.Warn().Str("signal", sig.String()).Msg("received signal, cancelling running tasks, send it again to terminate immediately")
+		cancel()
+	}()
+
+	return rootCmd().ExecuteContext(ctx)
 }
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
+	"github.com/primelib/primecodegen-app/pkg/metrics"
+	"github.com/primelib/primecodegen-app/pkg/scheduler"
+	"github.com/primelib/primecodegen-app/pkg/webhook"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func serveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "receive GitHub and GitLab webhooks and poll upstream specs to run generation, updates and releases for the affected repositories",
+		Run: func(cmd *cobra.Command, args []string) {
+			listen, _ := cmd.Flags().GetString("listen")
+			queueSize, _ := cmd.Flags().GetInt("queue-size")
+			pollSchedule, _ := cmd.Flags().GetString("poll-schedule")
+			pollRefresh, _ := cmd.Flags().GetDuration("poll-refresh")
+			opts, err := executeOptionsFromFlags(cmd)
+			if err != nil {
+				log.Fatal().Err(err).Msg("invalid execution options")
+			}
+
+			// secrets
+			server := &webhook.Server{
+				GitHubSecret: os.Getenv("PRIMEAPP_GITHUB_WEBHOOK_SECRET"),
+				GitLabToken:  os.Getenv("PRIMEAPP_GITLAB_WEBHOOK_TOKEN"),
+			}
+			if server.GitHubSecret == "" && server.GitLabToken == "" {
+				log.Warn().Msg("PRIMEAPP_GITHUB_WEBHOOK_SECRET and PRIMEAPP_GITLAB_WEBHOOK_TOKEN are not set, all webhooks are rejected")
+			}
+
+			// platform
+			platform, err := vcsapp.GetPlatformFromEnvironment()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to configure platform from environment")
+			}
+			if os.Getenv(vcsapp.GitlabServer) != "" && os.Getenv(vcsapp.GitlabAccessToken) != "" {
+				client, err := gitlab.NewClient(os.Getenv(vcsapp.GitlabAccessToken), gitlab.WithBaseURL(os.Getenv(vcsapp.GitlabServer)+"/api/v4"))
+				if err != nil {
+					log.Fatal().Err(err).Msg("failed to create gitlab client")
+				}
+				server.GitLabAccess = webhook.GitLabMemberAccess(client)
+			}
+
+			// queue
+			ctx := cmd.Context()
+			runner := webhook.NewRunner(platform, opts)
+			runner.CacheTTL, _ = cmd.Flags().GetDuration("repository-cache")
+			server.Queue = webhook.NewQueue(queueSize, runner.Run)
+			server.Queue.Start(ctx, opts.Concurrency)
+
+			// upstream polling
+			if pollSchedule != "" {
+				if _, err := scheduler.ParseSchedule(pollSchedule); err != nil {
+					log.Fatal().Err(err).Msg("invalid --poll-schedule")
+				}
+			}
+			poller := scheduler.New(platform, opts.Filter, pollSchedule, server.Queue.Enqueue)
+			poller.Timeout = opts.Steps.Fetch
+			poller.Concurrency, _ = cmd.Flags().GetInt("poll-concurrency")
+			go poller.Run(ctx, pollRefresh)
+
+			// http
+			mux := http.NewServeMux()
+			mux.Handle("/webhook", server)
+			mux.Handle("/metrics", metrics.Handler())
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			httpServer := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				<-ctx.Done()
+				log.Info().Msg("shutting down, cancelling running jobs")
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				_ = httpServer.Shutdown(shutdownCtx)
+			}()
+			log.Info().Str("listen", listen).Msg("receiving webhooks on /webhook")
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal().Err(err).Msg("webhook server stopped")
+			}
+
+			// wait until the cancelled jobs removed their temp directories
+			server.Queue.Close()
+			log.Info().Msg("webhook server stopped")
+		},
+	}
+	cmd.Flags().String("listen", ":8080", "Address the webhook server listens on")
+	cmd.Flags().Int("queue-size", 100, "Maximum number of queued jobs, further webhooks are rejected")
+	cmd.Flags().String("poll-schedule", "", "Cron schedule to poll the spec sources of repositories without spec.schedule, empty only polls repositories with a schedule")
+	cmd.Flags().Duration("poll-refresh", time.Hour, "Interval to reload the repositories and their polling schedules")
+	cmd.Flags().Int("poll-concurrency", 4, "Number of repositories whose spec sources are polled in parallel")
+	cmd.Flags().Duration("repository-cache", webhook.DefaultRepositoryCacheTTL, "How long the repository list is reused between webhook jobs, 0 lists the repositories for every job")
+	addExecuteFlags(cmd)
+
+	return cmd
+}
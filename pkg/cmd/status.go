@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cidverse/cidverseutils/core/clioutputwriter"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
+	"github.com/primelib/primecodegen-app/pkg/report"
+	"github.com/primelib/primecodegen-app/pkg/status"
+	"github.com/spf13/cobra"
+)
+
+func statusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "status",
+		Aliases: []string{"s"},
+		Run: func(cmd *cobra.Command, args []string) {
+			format, _ := cmd.Flags().GetString("format")
+			columns, _ := cmd.Flags().GetStringSlice("columns")
+			compare, _ := cmd.Flags().GetString("compare")
+
+			// previous run
+			var previous *report.Report
+			if compare != "" {
+				var err error
+				previous, err = report.Load(compare)
+				if err != nil {
+					slog.Error("failed to load report", "err", err)
+					os.Exit(1)
+				}
+			}
+
+			// platform
+			platform, err := vcsapp.GetPlatformFromEnvironment()
+			if err != nil {
+				slog.Error("failed to configure platform from environment", "err", err)
+				os.Exit(1)
+			}
+
+			// query repositories
+			repos, err := platform.Repositories(api.RepositoryListOpts{
+				IncludeBranches:   false,
+				IncludeCommitHash: true,
+			})
+			if err != nil {
+				slog.Error("failed to list repositories", "err", err)
+				os.Exit(1)
+			}
+
+			// data
+			var statuses []status.RepositoryStatus
+			for _, repo := range repos {
+				statuses = append(statuses, status.Collect(cmd.Context(), platform, repo, previous))
+			}
+			data := statusData(statuses, time.Now())
+
+			// filter columns
+			if len(columns) > 0 {
+				data = clioutputwriter.FilterColumns(data, columns)
+			}
+
+			// print
+			err = clioutputwriter.PrintData(os.Stdout, data, clioutputwriter.Format(format))
+			if err != nil {
+				slog.Error("failed to print data", "err", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringP("format", "f", string(clioutputwriter.DefaultOutputFormat()), fmt.Sprintf("output format %s", clioutputwriter.SupportedOutputFormats()))
+	cmd.Flags().StringSliceP("columns", "c", []string{}, "columns to display")
+	cmd.Flags().String("compare", "", "JSON run report of a previous run, its source digests take precedence over the committed source files to detect upstream spec changes")
+
+	return cmd
+}
+
+// statusData renders one row per repository, values that are not available are shown as "-"
+func statusData(statuses []status.RepositoryStatus, now time.Time) clioutputwriter.TabularData {
+	data := clioutputwriter.TabularData{
+		Headers: []string{"REPOSITORY", "CONFIG", "PRESETS", "SPEC_UPDATED", "PR", "PR_AGE", "LAST_TAG", "UPSTREAM"},
+		Rows:    [][]interface{}{},
+	}
+	for _, s := range statuses {
+		presets := strings.Join(s.Presets, ",")
+		if presets == "" {
+			presets = "-"
+		}
+		specUpdated := "-"
+		if s.SpecUpdated != nil {
+			specUpdated = s.SpecUpdated.Format(time.DateOnly)
+		}
+		pr, prAge := "-", "-"
+		if s.MergeRequest != nil {
+			pr = "open"
+			if s.MergeRequest.IsDraft {
+				pr = "draft"
+			}
+			prAge = status.FormatAge(s.MergeRequestCreated, now)
+		}
+		lastTag := s.LastTag
+		if lastTag == "" {
+			lastTag = "-"
+		}
+
+		data.Rows = append(data.Rows, []interface{}{s.Repository, s.Config, presets, specUpdated, pr, prAge, lastTag, s.Upstream})
+	}
+
+	return data
+}
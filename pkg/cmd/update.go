@@ -1,14 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path"
 
+	"github.com/cidverse/cidverseutils/core/clioutputwriter"
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
 	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
 	"github.com/primelib/primecodegen-app/pkg/config"
 	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/report"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
 	"github.com/primelib/primecodegen-app/pkg/tasks/codegeneration"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -20,23 +25,36 @@ func updateCmd() *cobra.Command {
 		Aliases: []string{"u"},
 		Run: func(cmd *cobra.Command, args []string) {
 			dir, _ := cmd.Flags().GetString("dir")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			format, _ := cmd.Flags().GetString("format")
+			reportFile, _ := cmd.Flags().GetString("report")
 
+			rep := report.New("update")
 			if dir == "" {
-				updateTaskApp()
+				opts, err := executeOptionsFromFlags(cmd)
+				if err != nil {
+					log.Fatal().Err(err).Msg("invalid execution options")
+				}
+				opts.Report = rep
+				updateTaskApp(cmd.Context(), opts, reportFile)
 			} else {
-				updateLocal(dir)
+				updateLocal(cmd.Context(), dir, dryRun, stepTimeoutsFromFlags(cmd), clioutputwriter.Format(format), rep)
+				writeReport(rep, reportFile)
 			}
 		},
 	}
 	cmd.Flags().Bool("dry-run", false, "Perform a dry run without making any changes")
 	cmd.Flags().String("dir", "", "Directory of the project for local code generation")
+	cmd.Flags().StringP("format", "f", string(clioutputwriter.DefaultOutputFormat()), fmt.Sprintf("output format of the dry run patch report %s", clioutputwriter.SupportedOutputFormats()))
+	cmd.Flags().String("report", "", "Write a JSON run report to the given file")
+	addExecuteFlags(cmd)
 
 	return cmd
 }
 
-func updateTaskApp() {
+func updateTaskApp(ctx context.Context, opts tasks.ExecuteOptions, reportFile string) {
 	// tasks
-	tasks := []taskcommon.Task{codegeneration.NewTask()}
+	taskList := []taskcommon.Task{codegeneration.NewTask(opts.Report, opts.Steps)}
 
 	// platform
 	platform, err := vcsapp.GetPlatformFromEnvironment()
@@ -45,13 +63,14 @@ func updateTaskApp() {
 	}
 
 	// execute
-	err = vcsapp.ExecuteTasks(platform, tasks)
+	err = tasks.ExecuteTasks(ctx, platform, taskList, opts)
+	writeReport(opts.Report, reportFile)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to execute generate task")
 	}
 }
 
-func updateLocal(dir string) {
+func updateLocal(ctx context.Context, dir string, dryRun bool, timeouts primelib.Timeouts, format clioutputwriter.Format, rep *report.Report) {
 	configPath := path.Join(dir, config.ConfigFileName)
 	bytes, err := os.ReadFile(configPath)
 	if err != nil {
@@ -66,8 +85,60 @@ func updateLocal(dir string) {
 
 	// for each module
 	log.Info().Str("dir", dir).Str("config", configPath).Msg("running local update")
-	err = primelib.Update(dir, conf, api.Repository{})
+	result, err := primelib.Update(ctx, dir, conf, api.Repository{}, primelib.UpdateOptions{DryRun: dryRun, Timeouts: timeouts})
+	entry := rep.Repository(dir, "update")
+	entry.AddUpdate(result)
+	entry.Finish(err)
 	if err != nil {
 		log.Warn().Err(err).Msg("failed to update spec")
 	}
+
+	// print patch report
+	if dryRun {
+		err = clioutputwriter.PrintData(os.Stdout, patchReportData(result), format)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to print patch report")
+		}
+	}
+}
+
+// patchReportData lists every touched target of each patch, all targets that matched nothing, lint findings, renamed operationIds and schemas and conversion losses
+func patchReportData(result primelib.UpdateResult) clioutputwriter.TabularData {
+	data := clioutputwriter.TabularData{
+		Headers: []string{"STEP", "STATUS", "TARGET"},
+		Rows:    [][]interface{}{},
+	}
+	for _, r := range result.Patches {
+		for _, t := range r.Touched {
+			data.Rows = append(data.Rows, []interface{}{r.Step(), "touched", t})
+		}
+	}
+	for _, u := range result.Unmatched {
+		data.Rows = append(data.Rows, []interface{}{u.Step, "missed", u.Target})
+	}
+	for _, f := range result.Lint {
+		data.Rows = append(data.Rows, []interface{}{"lint " + f.Rule, string(f.Severity), f.Pointer})
+	}
+	for _, r := range result.OperationIdRenames {
+		data.Rows = append(data.Rows, []interface{}{"operationId", "renamed", r.Old + " -> " + r.New})
+	}
+	for _, r := range result.SchemaRenames {
+		status := "renamed"
+		if r.Extracted {
+			status = "extracted"
+		}
+		data.Rows = append(data.Rows, []interface{}{"schema", status, r.Old + " -> " + r.New})
+	}
+	for _, l := range result.ConversionLosses {
+		data.Rows = append(data.Rows, []interface{}{"convert", "lost", l.Pointer})
+	}
+
+	return data
+}
+
+// writeReport writes the run report if a report file is configured
+func writeReport(rep *report.Report, file string) {
+	if err := rep.Write(file); err != nil {
+		log.Error().Err(err).Str("file", file).Msg("failed to write run report")
+	}
 }
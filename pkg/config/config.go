@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -30,6 +33,24 @@ func (c Configuration) MultiLanguage() bool {
 	return (c.Presets.EnabledCount() + len(c.Generators)) > 1
 }
 
+// Validate checks the required fields of the spec configuration
+func (c Configuration) Validate() error {
+	var errs []error
+	if len(c.Spec.Sources) == 0 {
+		errs = append(errs, fmt.Errorf("spec.sources is required"))
+	}
+	for i, s := range c.Spec.Sources {
+		if s.URL == "" && s.File == "" {
+			errs = append(errs, fmt.Errorf("spec.sources[%d] requires a url or file", i))
+		}
+	}
+	if !c.Spec.Type.IsOpenAPI3() && c.Spec.Type != SpecTypeSwagger2 {
+		errs = append(errs, fmt.Errorf("spec.type %q is not supported", c.Spec.Type))
+	}
+
+	return errors.Join(errs...)
+}
+
 type Repository struct {
 	Name          string `yaml:"name"`
 	Description   string `yaml:"description"`
@@ -82,6 +103,19 @@ func (c Presets) EnabledCount() int {
 	return enabledCount
 }
 
+// EnabledNames returns the names of all enabled presets
+func (c Presets) EnabledNames() []string {
+	var names []string
+	for name, enabled := range map[string]bool{"go": c.Go.Enabled, "java": c.Java.Enabled, "python": c.Python.Enabled, "csharp": c.CSharp.Enabled, "typescript": c.Typescript.Enabled} {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
 type OpenApiGeneratorOptions struct {
 	Enabled     bool     `yaml:"enabled"`
 	IgnoreFiles []string `yaml:"ignoreFiles"`
@@ -138,10 +172,24 @@ type Spec struct {
 	Type SpecType `yaml:"type" required:"true"`
 	// Customization allows overwriting certain parts of the specification
 	Customization Customization `yaml:"customization"`
+	// Merge configures how conflicts are resolved when merging multiple sources
+	Merge SpecMerge `yaml:"merge"`
 	// InputPatches are applied to the source specifications before merging
-	InputPatches []string `yaml:"inputPatches"`
+	InputPatches []SpecPatch `yaml:"inputPatches"`
 	// Patches are the patches that are applied to the specification
-	Patches []string `yaml:"patches"`
+	Patches []SpecPatch `yaml:"patches"`
+	// Strict fails the update if a patch or customization target does not match anything
+	Strict bool `yaml:"strict"`
+	// Lint configures the rules that are checked against the customized spec
+	Lint SpecLint `yaml:"lint"`
+	// Output configures the formatting of the spec file
+	Output SpecOutput `yaml:"output"`
+	// Bundle copies all external references of the sources into the components, so the spec file is self-contained
+	Bundle bool `yaml:"bundle"`
+	// RemoteRefs configures which remote references may be resolved, remote references are disabled by default
+	RemoteRefs SpecRemoteRefs `yaml:"remoteRefs"`
+	// Schedule is the cron expression used to poll the sources for changes in server mode, e.g. "0 */6 * * *"
+	Schedule string `yaml:"schedule"`
 }
 
 func (s Spec) UrlSlice() []string {
@@ -171,6 +219,67 @@ type SpecSource struct {
 	Type   SpecType   `yaml:"type"`
 }
 
+// BuiltInPatchPrefix marks the name of a built-in patch, names without the prefix reference patches of primecodegen
+const BuiltInPatchPrefix = "builtin:"
+
+// SpecPatch references a patch by name or a patch file, a plain string is treated as the name of a primecodegen patch unless it has the builtin: prefix
+type SpecPatch struct {
+	Name string    `yaml:"name"` // Name of the primecodegen or built-in patch
+	Type PatchType `yaml:"type"` // Type of the patch, detected from the file content if empty, named patches default to primecodegen
+	File string    `yaml:"file"` // File is the path to the patch file, relative to the project directory
+}
+
+func (p *SpecPatch) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		p.Name = value.Value
+	} else {
+		type plain SpecPatch
+		if err := value.Decode((*plain)(p)); err != nil {
+			return err
+		}
+	}
+
+	if p.File != "" || (p.Type != "" && p.Type != PatchTypeBuiltIn) {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(p.Name, BuiltInPatchPrefix); ok {
+		p.Name = name
+		p.Type = PatchTypeBuiltIn
+	} else if p.Type == "" {
+		p.Type = PatchTypePrimeCodeGen
+	}
+
+	return nil
+}
+
+// SpecMerge configures the conflict strategy for each part of the specification
+type SpecMerge struct {
+	Paths      MergeStrategy `yaml:"paths"`      // Paths conflict if the same operation is defined with different content
+	Components MergeStrategy `yaml:"components"` // Components conflict if the same name is defined with different content
+	Tags       MergeStrategy `yaml:"tags"`       // Tags conflict if the same name is defined with different content
+	Servers    MergeStrategy `yaml:"servers"`    // Servers conflict if the same url is defined with different content
+}
+
+// SpecOutput configures the formatting of the spec file, JSON is written if the file has a .json extension
+type SpecOutput struct {
+	Canonical       bool `yaml:"canonical"`       // Canonical writes keys in openapi field order with sorted paths and components, so repeated updates produce identical files
+	Indent          int  `yaml:"indent"`          // Indent is the number of spaces used for indentation, defaults to 2
+	TrailingNewline bool `yaml:"trailingNewline"` // TrailingNewline ends the file with a newline
+}
+
+// SpecRemoteRefs is the policy for remote references, only hosts on the allow-list are fetched
+type SpecRemoteRefs struct {
+	Hosts    []string `yaml:"hosts"`    // Hosts are the allowed hosts, *.example.com allows all subdomains
+	Schemes  []string `yaml:"schemes"`  // Schemes are the allowed url schemes, defaults to https
+	MaxCount int      `yaml:"maxCount"` // MaxCount is the maximum number of remote references fetched per update, defaults to 50
+	MaxSize  int64    `yaml:"maxSize"`  // MaxSize is the maximum size of a remote reference in bytes, defaults to 10 MiB
+}
+
+// SpecLint configures the lint ruleset, rules that are not configured use their default severity
+type SpecLint struct {
+	Rules map[string]LintSeverity `yaml:"rules"`
+}
+
 type Customization struct {
 	Title       string                `yaml:"title"`
 	Summary     string                `yaml:"summary"`
@@ -180,12 +289,48 @@ type Customization struct {
 	License     CustomizationLicense  `yaml:"license"`
 	Servers     []CustomizationServer `yaml:"servers"`
 
+	// Host, BasePath and Schemes override the target of swagger 2.0 specifications
+	Host     string   `yaml:"host"`
+	BasePath string   `yaml:"basePath"`
+	Schemes  []string `yaml:"schemes"`
+
+	// SecuritySchemes adds or overrides security schemes in the components
+	SecuritySchemes map[string]CustomizationSecurityScheme `yaml:"securitySchemes"`
+	// Security replaces the global security requirements, mapping security scheme names to the required scopes
+	Security []map[string][]string `yaml:"security"`
+
+	// OperationIds normalizes the operationIds of all operations
+	OperationIds CustomizationOperationIds `yaml:"operationIds"`
+
+	// Schemas renames schemas and extracts inline schemas into named components
+	Schemas CustomizationSchemas `yaml:"schemas"`
+
 	// Prune operations, tags and schemas
 	PruneOperations []string `yaml:"pruneOperations"`
 	PruneTags       []string `yaml:"pruneTags"`
 	PruneSchemas    []string `yaml:"pruneSchemas"`
 }
 
+type CustomizationOperationIds struct {
+	StripPrefixes []string          `yaml:"stripPrefixes"` // StripPrefixes are removed from the start of operationIds
+	StripSuffixes []string          `yaml:"stripSuffixes"` // StripSuffixes are removed from the end of operationIds
+	Derive        bool              `yaml:"derive"`        // Derive missing operationIds from method and path
+	CamelCase     bool              `yaml:"camelCase"`     // CamelCase converts operationIds to lower camel case
+	Deduplicate   bool              `yaml:"deduplicate"`   // Deduplicate appends a number to operationIds that are used multiple times
+	Rename        map[string]string `yaml:"rename"`        // Rename maps upstream operationIds to new names, renamed ids are not normalized
+}
+
+type CustomizationSchemas struct {
+	Rules         []CustomizationSchemaRule `yaml:"rules"`         // Rules rename schemas matching a regular expression, applied in order
+	Rename        map[string]string         `yaml:"rename"`        // Rename maps upstream schema names to new names, renamed schemas are not matched by rules
+	ExtractInline bool                      `yaml:"extractInline"` // ExtractInline moves inline object schemas into named schemas
+}
+
+type CustomizationSchemaRule struct {
+	Pattern string `yaml:"pattern"` // Pattern is a regular expression that is matched against the schema name
+	Replace string `yaml:"replace"` // Replace is the replacement, supports $1 style capture group references
+}
+
 type CustomizationContact struct {
 	Name  string `yaml:"name"`
 	URL   string `yaml:"url"`
@@ -199,8 +344,40 @@ type CustomizationLicense struct {
 }
 
 type CustomizationServer struct {
-	URL         string `yaml:"url"`
-	Description string `yaml:"description"`
+	URL         string                                 `yaml:"url"`
+	Description string                                 `yaml:"description"`
+	Variables   map[string]CustomizationServerVariable `yaml:"variables"` // Variables override the upstream variables of the server with the same url
+}
+
+type CustomizationServerVariable struct {
+	Enum        []string `yaml:"enum"`
+	Default     string   `yaml:"default"`
+	Description string   `yaml:"description"`
+}
+
+type CustomizationSecurityScheme struct {
+	Type             string                  `yaml:"type"` // Type is one of apiKey, http, mutualTLS, oauth2 or openIdConnect
+	Description      string                  `yaml:"description"`
+	Name             string                  `yaml:"name"`
+	In               string                  `yaml:"in"`
+	Scheme           string                  `yaml:"scheme"`
+	BearerFormat     string                  `yaml:"bearerFormat"`
+	Flows            CustomizationOAuthFlows `yaml:"flows"`
+	OpenIdConnectURL string                  `yaml:"openIdConnectUrl"`
+}
+
+type CustomizationOAuthFlows struct {
+	Implicit          *CustomizationOAuthFlow `yaml:"implicit"`
+	Password          *CustomizationOAuthFlow `yaml:"password"`
+	ClientCredentials *CustomizationOAuthFlow `yaml:"clientCredentials"`
+	AuthorizationCode *CustomizationOAuthFlow `yaml:"authorizationCode"`
+}
+
+type CustomizationOAuthFlow struct {
+	AuthorizationURL string            `yaml:"authorizationUrl"`
+	TokenURL         string            `yaml:"tokenUrl"`
+	RefreshURL       string            `yaml:"refreshUrl"`
+	Scopes           map[string]string `yaml:"scopes"`
 }
 
 type GeneratorConfig struct {
@@ -239,6 +416,18 @@ func FromString(content string) (Configuration, error) {
 			config.Spec.Sources[i].Format = SourceTypeSpec
 		}
 	}
+	if config.Spec.Merge.Paths == "" {
+		config.Spec.Merge.Paths = MergeStrategyKeepFirst
+	}
+	if config.Spec.Merge.Components == "" {
+		config.Spec.Merge.Components = MergeStrategyKeepFirst
+	}
+	if config.Spec.Merge.Tags == "" {
+		config.Spec.Merge.Tags = MergeStrategyKeepFirst
+	}
+	if config.Spec.Merge.Servers == "" {
+		config.Spec.Merge.Servers = MergeStrategyKeepFirst
+	}
 	if config.Spec.Customization.Title == "" {
 		config.Spec.Customization.Title = config.Name
 	}
@@ -20,6 +20,39 @@ const (
 type SpecType string
 
 const (
-	SpecTypeOpenAPI3 SpecType = "openapi3"
-	SpecTypeSwagger2 SpecType = "swagger2"
+	SpecTypeOpenAPI3  SpecType = "openapi3"  // openapi 3, the version of the upstream spec is kept
+	SpecTypeOpenAPI30 SpecType = "openapi30" // openapi 3.0, 3.1 specs are converted
+	SpecTypeOpenAPI31 SpecType = "openapi31" // openapi 3.1, 3.0 specs are converted
+	SpecTypeSwagger2  SpecType = "swagger2"
+)
+
+// IsOpenAPI3 reports whether the spec type is any openapi 3 version
+func (t SpecType) IsOpenAPI3() bool {
+	return t == SpecTypeOpenAPI3 || t == SpecTypeOpenAPI30 || t == SpecTypeOpenAPI31
+}
+
+type MergeStrategy string
+
+const (
+	MergeStrategyError     MergeStrategy = "error"      // fail the merge if a conflict is detected
+	MergeStrategyKeepFirst MergeStrategy = "keep-first" // keep the value of the first specification that defines it
+	MergeStrategyKeepLast  MergeStrategy = "keep-last"  // keep the value of the last specification that defines it
+)
+
+type PatchType string
+
+const (
+	PatchTypePrimeCodeGen PatchType = "primecodegen" // patch of primecodegen referenced by name, applied with primecodegen openapi-patch
+	PatchTypeBuiltIn      PatchType = "builtin"      // built-in patch referenced by name
+	PatchTypeJSONPatch    PatchType = "json-patch"   // RFC 6902 JSON Patch
+	PatchTypeMergePatch   PatchType = "merge-patch"  // RFC 7386 JSON Merge Patch
+	PatchTypeOverlay      PatchType = "overlay"      // OpenAPI Overlay 1.0
+)
+
+type LintSeverity string
+
+const (
+	LintSeverityError LintSeverity = "error" // findings block the update
+	LintSeverityWarn  LintSeverity = "warn"  // findings are reported in the update PR
+	LintSeverityOff   LintSeverity = "off"   // the rule is disabled
 )
@@ -0,0 +1,122 @@
+package failure
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
+	"github.com/primelib/primecodegen-app/pkg/generator"
+)
+
+// configSnippetLines limits the lines of primelib.yaml included in a failure report
+const configSnippetLines = 50
+
+// outputReportSize limits the bytes of generator output included in a failure report
+const outputReportSize = 4096
+
+//go:embed templates/issue.gohtml
+var issueTemplate []byte
+
+// StepError records the step of a task that failed
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Step wraps the error with the name of the failing step, nil errors stay nil
+func Step(step string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &StepError{Step: step, Err: err}
+}
+
+// Failure contains everything a repository owner needs to understand why a task failed
+type Failure struct {
+	Repository string
+	Task       string
+	Step       string // Step is the failing step of the task, empty if unknown
+	Error      string
+	Output     string // Output is the end of the generator process output, empty if no process failed
+	Config     string // Config is a snippet of primelib.yaml
+}
+
+// New collects the failing step and generator output from the error
+func New(repository string, task string, err error, config string) Failure {
+	f := Failure{
+		Repository: repository,
+		Task:       task,
+		Error:      err.Error(),
+		Config:     snippet(config, configSnippetLines),
+	}
+
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		f.Step = stepErr.Step
+	}
+	var cmdErr *generator.CommandError
+	if errors.As(err, &cmdErr) {
+		f.Error = strings.Replace(f.Error, cmdErr.Error(), cmdErr.Err.Error(), 1) // the output is reported separately
+		f.Output = strings.TrimSpace(cmdErr.Tail(outputReportSize))
+	}
+
+	return f
+}
+
+// Label is set on all failure issues, only issues carrying it are updated or closed by the app
+const Label = "primelib-failure"
+
+// Marker identifies the issue of a task, it is hidden in the issue body
+func Marker(task string) string {
+	return fmt.Sprintf("<!-- primelib-failure: %s -->", task)
+}
+
+// Title returns the issue title
+func (f Failure) Title() string {
+	return fmt.Sprintf("PrimeLib %s failed", f.Task)
+}
+
+// Summary returns a single line description, used for commit statuses
+func (f Failure) Summary() string {
+	if f.Step != "" {
+		return fmt.Sprintf("%s failed: %s", f.Step, f.Error)
+	}
+	return fmt.Sprintf("%s failed: %s", f.Task, f.Error)
+}
+
+// Body renders the issue body
+func (f Failure) Body(platformSlug string) (string, error) {
+	body, err := vcsapp.Render(string(issueTemplate), map[string]interface{}{
+		"Marker":       Marker(f.Task),
+		"Failure":      f,
+		"PlatformSlug": platformSlug,
+		"Footer":       os.Getenv("PRIMEAPP_FOOTER_HIDE") != "true",
+		"FooterCustom": os.Getenv("PRIMEAPP_FOOTER_CUSTOM"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render issue template: %w", err)
+	}
+
+	return string(body), nil
+}
+
+// snippet returns the first lines of the content
+func snippet(content string, lines int) string {
+	parts := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(parts) <= lines {
+		return strings.Join(parts, "\n")
+	}
+
+	return strings.Join(parts[:lines], "\n") + fmt.Sprintf("\n# ... %d more lines", len(parts)-lines)
+}
@@ -0,0 +1,60 @@
+package failure
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	cmdErr := &generator.CommandError{Err: errors.New("exit status 1"), Output: "Exception in thread main\n  at Generator.run\n"}
+	err := Step("generate", fmt.Errorf("failed to generate: %w", cmdErr))
+
+	f := New("primelib/example", "generate", err, "name: example\nspec:\n  type: openapi3\n")
+	assert.Equal(t, "generate", f.Step)
+	assert.Equal(t, "failed to generate: exit status 1", f.Error)
+	assert.Equal(t, "Exception in thread main\n  at Generator.run", f.Output)
+	assert.Equal(t, "name: example\nspec:\n  type: openapi3", f.Config)
+	assert.Equal(t, "generate failed: failed to generate: exit status 1", f.Summary())
+
+	f = New("primelib/example", "release", errors.New("failed to create tag"), "")
+	assert.Empty(t, f.Step)
+	assert.Empty(t, f.Output)
+	assert.Equal(t, "release failed: failed to create tag", f.Summary())
+}
+
+func TestBody(t *testing.T) {
+	t.Setenv("PRIMEAPP_FOOTER_HIDE", "true")
+	f := Failure{Task: "generate", Step: "generate", Error: "failed to generate: exit status 1", Output: "Exception", Config: "name: example"}
+
+	body, err := f.Body("github")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(body, Marker("generate")))
+	assert.Contains(t, body, "failed at step **generate**")
+	assert.Contains(t, body, "### Generator Output\n\n```\nException\n```")
+	assert.Contains(t, body, "```yaml\nname: example\n```")
+	assert.NotContains(t, body, "PrimeLib GitHub App")
+}
+
+func TestSnippet(t *testing.T) {
+	assert.Equal(t, "a\nb", snippet("a\nb\n", 2))
+	assert.Equal(t, "a\nb\n# ... 2 more lines", snippet("a\nb\nc\nd\n", 2))
+}
+
+func TestParseModes(t *testing.T) {
+	modes, err := ParseModes([]string{"issue", "status", "issue"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Mode{ModeIssue, ModeStatus}, modes)
+
+	modes, err = ParseModes([]string{"none"})
+	assert.NoError(t, err)
+	assert.Empty(t, modes)
+	assert.Nil(t, NewReporter(modes))
+
+	_, err = ParseModes([]string{"email"})
+	assert.Error(t, err)
+}
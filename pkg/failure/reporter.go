@@ -0,0 +1,116 @@
+package failure
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/platformutil"
+	"github.com/rs/zerolog"
+)
+
+// Mode selects how failures are reported to the platform
+type Mode string
+
+const (
+	ModeIssue  Mode = "issue"  // ModeIssue opens or updates an issue and closes it after a successful run
+	ModeStatus Mode = "status" // ModeStatus sets a commit status on the default branch
+	ModeNone   Mode = "none"   // ModeNone only logs failures
+)
+
+// ParseModes validates the failure report modes
+func ParseModes(values []string) ([]Mode, error) {
+	var modes []Mode
+	for _, v := range values {
+		switch Mode(v) {
+		case ModeIssue, ModeStatus:
+			if !slices.Contains(modes, Mode(v)) {
+				modes = append(modes, Mode(v))
+			}
+		case ModeNone:
+		default:
+			return nil, fmt.Errorf("invalid failure report mode %q, expected %s, %s or %s", v, ModeIssue, ModeStatus, ModeNone)
+		}
+	}
+
+	return modes, nil
+}
+
+// Reporter reports the outcome of a task to the repository
+type Reporter struct {
+	Modes []Mode
+}
+
+// NewReporter creates a reporter, nil if no mode is enabled
+func NewReporter(modes []Mode) *Reporter {
+	if len(modes) == 0 {
+		return nil
+	}
+	return &Reporter{Modes: modes}
+}
+
+// Report publishes a failure or resolves a previous failure if err is nil, platforms without support are skipped
+func (r *Reporter) Report(platform api.Platform, repo api.Repository, task string, err error, logger zerolog.Logger) error {
+	if r == nil {
+		return nil
+	}
+
+	var f Failure
+	if err != nil {
+		content, _ := platform.FileContent(repo, repo.DefaultBranch, config.ConfigFileName)
+		f = New(repo.Namespace+"/"+repo.Name, task, err, content)
+	}
+
+	var errs []error
+	for _, mode := range r.Modes {
+		var reportErr error
+		switch mode {
+		case ModeIssue:
+			reportErr = r.reportIssue(platform, repo, task, err, f, logger)
+		case ModeStatus:
+			reportErr = r.reportStatus(repo, task, err, f)
+		}
+		if errors.Is(reportErr, platformutil.ErrUnsupportedPlatform) {
+			logger.Debug().Str("mode", string(mode)).Str("platform", platform.Slug()).Msg("failure report is not supported by the platform")
+			continue
+		}
+		if reportErr != nil {
+			errs = append(errs, reportErr)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Reporter) reportIssue(platform api.Platform, repo api.Repository, task string, err error, f Failure, logger zerolog.Logger) error {
+	if err == nil {
+		number, closeErr := platformutil.CloseIssue(repo, Label, Marker(task), fmt.Sprintf("The `%s` task succeeded, closing this issue.", task))
+		if number > 0 {
+			logger.Info().Int("issue", number).Msg("closed failure issue")
+		}
+		return closeErr
+	}
+
+	body, err := f.Body(platform.Slug())
+	if err != nil {
+		return err
+	}
+	number, err := platformutil.CreateOrUpdateIssue(repo, Label, Marker(task), f.Title(), body)
+	if err != nil {
+		return err
+	}
+	logger.Info().Int("issue", number).Msg("reported failure as issue")
+
+	return nil
+}
+
+func (r *Reporter) reportStatus(repo api.Repository, task string, err error, f Failure) error {
+	statusContext := "primelib/" + task
+	if err == nil {
+		return platformutil.SetCommitStatus(repo, repo.CommitHash, "success", statusContext, fmt.Sprintf("%s succeeded", task))
+	}
+
+	return platformutil.SetCommitStatus(repo, repo.CommitHash, "failure", statusContext, f.Summary())
+}
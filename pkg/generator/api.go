@@ -1,7 +1,10 @@
 package generator
 
 import (
+	"context"
+
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/rs/zerolog"
 )
 
 type Config struct {
@@ -13,11 +16,12 @@ type Config struct {
 type GenerateOptions struct {
 	ProjectDirectory string
 	OutputDirectory  string
+	Logger           zerolog.Logger // Logger receives the generator output, tagged with repository and generator
 }
 
 // Generator provides a common interface for all generators
 type Generator interface {
-	Name() string                        // Name returns the name of the generator
-	GetOutputName() string               // GetOutputName returns the name of the output dir for e.g. multi-language SDKs
-	Generate(opts GenerateOptions) error // Generate runs the code generation
+	Name() string                                             // Name returns the name of the generator
+	GetOutputName() string                                    // GetOutputName returns the name of the output dir for e.g. multi-language SDKs
+	Generate(ctx context.Context, opts GenerateOptions) error // Generate runs the code generation, the generator process is killed once ctx is done
 }
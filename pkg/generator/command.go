@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/primelib/primecodegen-app/pkg/util"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// outputLimit is the number of bytes of the generator output kept per invocation
+	outputLimit = 64 * 1024
+	// errorTailSize is the number of bytes of the output tail included in the error message
+	errorTailSize = 512
+)
+
+// CommandError is returned if a generator process fails, it contains the end of the captured output
+type CommandError struct {
+	Err       error
+	Generator string
+	Output    string // Output is the end of the combined stdout and stderr output, limited to outputLimit bytes
+}
+
+func (e *CommandError) Error() string {
+	tail := e.Tail(errorTailSize)
+	if tail == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s, output:\n%s", e.Err.Error(), tail)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Tail returns the last size bytes of the output, starting at a line boundary if possible
+func (e *CommandError) Tail(size int) string {
+	return tail(e.Output, size)
+}
+
+// output captures the combined output of a process, it keeps the last limit bytes
+type output struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (o *output) append(p []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.buf = append(o.buf, p...)
+	if len(o.buf) > o.limit {
+		o.buf = append([]byte(nil), o.buf[len(o.buf)-o.limit:]...)
+	}
+}
+
+func (o *output) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return strings.TrimSpace(util.StripANSI(string(o.buf)))
+}
+
+// lineWriter logs each complete line of a process stream and adds it to the captured output
+type lineWriter struct {
+	logger  zerolog.Logger
+	stream  string
+	output  *output
+	pending []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(w.pending[:i+1])
+		w.pending = w.pending[i+1:]
+	}
+
+	// flush very long lines without a newline, so the pending buffer stays bounded
+	if len(w.pending) > outputLimit {
+		w.Flush()
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the remaining partial line
+func (w *lineWriter) Flush() {
+	if len(w.pending) > 0 {
+		w.line(append(w.pending, '\n'))
+		w.pending = nil
+	}
+}
+
+func (w *lineWriter) line(line []byte) {
+	w.output.append(line)
+	w.logger.Debug().Str("stream", w.stream).Msg(strings.TrimRight(util.StripANSI(string(line)), "\r\n"))
+}
+
+// runCommand runs a generator process, stdout and stderr are logged line by line and the end of the output is kept for the error
+func runCommand(ctx context.Context, cmd *exec.Cmd, generator string, logger zerolog.Logger) error {
+	captured := &output{limit: outputLimit}
+	stdout := &lineWriter{logger: logger, stream: "stdout", output: captured}
+	stderr := &lineWriter{logger: logger, stream: "stderr", output: captured}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	stdout.Flush()
+	stderr.Flush()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w: %w", ctxErr, err)
+		}
+		return &CommandError{Err: err, Generator: generator, Output: captured.String()}
+	}
+
+	return nil
+}
+
+// tail returns the last size bytes of s, cut at the first line break if there is one
+func tail(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	s = s[len(s)-size:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 && i < len(s)-1 {
+		s = s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/primelib/primecodegen-app/pkg/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommandCapturesOutput(t *testing.T) {
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs).With().Str("repository", "primelib/example").Logger()
+
+	err := runCommand(context.Background(), exec.Command("sh", "-c", "echo generating; echo 'model not found' >&2; exit 3"), "openapi-generator", logger)
+	require.Error(t, err)
+
+	var cmdErr *CommandError
+	require.True(t, errors.As(err, &cmdErr))
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, "openapi-generator", cmdErr.Generator)
+	assert.Contains(t, cmdErr.Output, "generating")
+	assert.Contains(t, cmdErr.Output, "model not found")
+	assert.Contains(t, err.Error(), "exit status 3, output:\n")
+	assert.Contains(t, err.Error(), "model not found")
+
+	assert.Contains(t, logs.String(), `"repository":"primelib/example","stream":"stdout","message":"generating"`)
+	assert.Contains(t, logs.String(), `"stream":"stderr","message":"model not found"`)
+}
+
+func TestRunCommandLimitsOutput(t *testing.T) {
+	err := runCommand(context.Background(), exec.Command("sh", "-c", "yes line | head -n 20000; exit 1"), "primecodegen", zerolog.Nop())
+
+	var cmdErr *CommandError
+	require.True(t, errors.As(err, &cmdErr))
+	assert.LessOrEqual(t, len(cmdErr.Output), outputLimit)
+	assert.True(t, strings.HasSuffix(cmdErr.Output, "line"))
+	assert.LessOrEqual(t, len(cmdErr.Tail(errorTailSize)), errorTailSize)
+}
+
+func TestRunCommandSuccess(t *testing.T) {
+	assert.NoError(t, runCommand(context.Background(), exec.Command("sh", "-c", "echo done"), "primecodegen", zerolog.Nop()))
+}
+
+func TestTail(t *testing.T) {
+	assert.Equal(t, "short", tail("short", 10))
+	assert.Equal(t, "third", tail("first\nsecond\nthird", 8))
+	assert.Equal(t, "abcdef", tail("123abcdef", 6))
+}
+
+func TestRunCommandCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := runCommand(ctx, util.Command(ctx, "sh", "-c", "echo started; sleep 30"), "openapi-generator", zerolog.Nop())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	var cmdErr *CommandError
+	if assert.ErrorAs(t, err, &cmdErr) {
+		assert.Equal(t, "started", cmdErr.Output)
+	}
+}
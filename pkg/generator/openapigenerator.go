@@ -1,16 +1,17 @@
 package generator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"slices"
 	"strings"
 
 	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/util"
 	"github.com/rs/zerolog/log"
 )
 
@@ -69,7 +70,7 @@ func (n *OpenAPIGenerator) GetOutputName() string {
 	return n.OutputName
 }
 
-func (n *OpenAPIGenerator) Generate(opts GenerateOptions) error {
+func (n *OpenAPIGenerator) Generate(ctx context.Context, opts GenerateOptions) error {
 	// create dir
 	_ = os.MkdirAll(opts.OutputDirectory, os.ModePerm)
 
@@ -80,7 +81,7 @@ func (n *OpenAPIGenerator) Generate(opts GenerateOptions) error {
 	}
 
 	// generate
-	err = n.generateCode(opts)
+	err = n.generateCode(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("failed to generate code: %w", err)
 	}
@@ -101,7 +102,7 @@ func (n *OpenAPIGenerator) writeIgnoreFilesFile(opts GenerateOptions) error {
 	return nil
 }
 
-func (n *OpenAPIGenerator) generateCode(opts GenerateOptions) error {
+func (n *OpenAPIGenerator) generateCode(ctx context.Context, opts GenerateOptions) error {
 	// auto generate config
 	tempConfigFile, tmpErr := os.CreateTemp("", "openapi-generator.json")
 	if tmpErr != nil {
@@ -169,12 +170,10 @@ func (n *OpenAPIGenerator) generateCode(opts GenerateOptions) error {
 	}...)
 	args = append(args, n.Args...)
 
-	cmd := exec.Command(executable, args...)
+	cmd := util.Command(ctx, executable, args...)
 	cmd.Dir = opts.ProjectDirectory
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 	log.Trace().Str("command", cmd.String()).Msg("executing code generation")
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd, n.Name(), opts.Logger); err != nil {
 		return fmt.Errorf("failed to execute code generation: %w", err)
 	}
 
@@ -1,11 +1,12 @@
 package generator
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 
 	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/util"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -36,12 +37,12 @@ func (n *PrimeCodeGenGenerator) GetOutputName() string {
 	return n.OutputName
 }
 
-func (n *PrimeCodeGenGenerator) Generate(opts GenerateOptions) error {
+func (n *PrimeCodeGenGenerator) Generate(ctx context.Context, opts GenerateOptions) error {
 	// create dir
 	_ = os.MkdirAll(opts.OutputDirectory, os.ModePerm)
 
 	// generate
-	err := n.generateCode(opts)
+	err := n.generateCode(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("failed to generate code: %w", err)
 	}
@@ -49,7 +50,7 @@ func (n *PrimeCodeGenGenerator) Generate(opts GenerateOptions) error {
 	return nil
 }
 
-func (n *PrimeCodeGenGenerator) generateCode(opts GenerateOptions) error {
+func (n *PrimeCodeGenGenerator) generateCode(ctx context.Context, opts GenerateOptions) error {
 	// primecodegen bin and args
 	executable := "primecodegen"
 	var args []string
@@ -82,12 +83,10 @@ func (n *PrimeCodeGenGenerator) generateCode(opts GenerateOptions) error {
 	}
 
 	allArgs := append(args, n.Args...)
-	cmd := exec.Command(executable, allArgs...)
+	cmd := util.Command(ctx, executable, allArgs...)
 	cmd.Dir = opts.ProjectDirectory
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 	log.Trace().Str("command", cmd.String()).Msg("executing code generation")
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd, n.Name(), opts.Logger); err != nil {
 		return fmt.Errorf("failed to execute code generation: %w", err)
 	}
 
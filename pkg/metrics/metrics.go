@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Merge request actions
+const (
+	MergeRequestOpened  = "opened"
+	MergeRequestUpdated = "updated"
+	MergeRequestSkipped = "skipped"
+)
+
+// Registry holds all metrics of the app
+var Registry = prometheus.NewRegistry()
+
+var (
+	repositoriesProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "primelib_repositories_processed_total",
+		Help: "Repositories processed per task and result.",
+	}, []string{"task", "result"})
+	specFetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "primelib_spec_fetch_duration_seconds",
+		Help:    "Latency of spec source downloads per host.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"host"})
+	specFetchFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "primelib_spec_fetch_failures_total",
+		Help: "Failed spec source downloads per host.",
+	}, []string{"host"})
+	generatorDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "primelib_generator_duration_seconds",
+		Help:    "Duration of generator runs per generator and result.",
+		Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200},
+	}, []string{"generator", "result"})
+	mergeRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "primelib_merge_requests_total",
+		Help: "Merge requests opened, updated or skipped because nothing changed.",
+	}, []string{"action"})
+	breakingChanges = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "primelib_breaking_changes_total",
+		Help: "Breaking changes detected in spec updates.",
+	})
+	tagsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "primelib_tags_created_total",
+		Help: "Release tags created.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		repositoriesProcessed,
+		specFetchDuration,
+		specFetchFailures,
+		generatorDuration,
+		mergeRequests,
+		breakingChanges,
+		tagsCreated,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RepositoryProcessed counts a task run for a repository
+func RepositoryProcessed(task string, err error) {
+	repositoriesProcessed.WithLabelValues(task, result(err)).Inc()
+}
+
+// SpecFetched records the latency of a spec download, failures are counted separately
+func SpecFetched(rawURL string, duration time.Duration, err error) {
+	h := host(rawURL)
+	specFetchDuration.WithLabelValues(h).Observe(duration.Seconds())
+	if err != nil {
+		specFetchFailures.WithLabelValues(h).Inc()
+	}
+}
+
+// GeneratorFinished records the duration of a generator run
+func GeneratorFinished(generator string, duration time.Duration, err error) {
+	generatorDuration.WithLabelValues(generator, result(err)).Observe(duration.Seconds())
+}
+
+// MergeRequest counts a merge request action
+func MergeRequest(action string) {
+	mergeRequests.WithLabelValues(action).Inc()
+}
+
+// BreakingChanges counts the breaking changes of a spec update
+func BreakingChanges(count int) {
+	breakingChanges.Add(float64(count))
+}
+
+// TagCreated counts a created release tag
+func TagCreated() {
+	tagsCreated.Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// host returns the host of the url, the path is left out to keep the label cardinality low
+func host(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
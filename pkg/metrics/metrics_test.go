@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	RepositoryProcessed("generate", nil)
+	RepositoryProcessed("generate", errors.New("failed"))
+	SpecFetched("https://api.example.com/openapi.yaml", 200*time.Millisecond, nil)
+	SpecFetched("https://api.example.com/v2/openapi.yaml", time.Second, errors.New("timeout"))
+	GeneratorFinished("java-httpclient", 42*time.Second, nil)
+	MergeRequest(MergeRequestOpened)
+	MergeRequest(MergeRequestSkipped)
+	BreakingChanges(3)
+	TagCreated()
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(repositoriesProcessed.WithLabelValues("generate", "failure")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(specFetchFailures.WithLabelValues("api.example.com")))
+	assert.Equal(t, 1, testutil.CollectAndCount(specFetchDuration))
+	assert.Equal(t, 3.0, testutil.ToFloat64(breakingChanges))
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	assert.Contains(t, string(body), `primelib_generator_duration_seconds_count{generator="java-httpclient",result="success"} 1`)
+	assert.Contains(t, string(body), `primelib_merge_requests_total{action="opened"} 1`)
+	assert.Contains(t, string(body), `primelib_tags_created_total 1`)
+}
+
+func TestHost(t *testing.T) {
+	assert.Equal(t, "api.example.com:8443", host("https://api.example.com:8443/openapi.yaml"))
+	assert.Equal(t, "unknown", host("openapi.yaml"))
+}
@@ -0,0 +1,262 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BundleOptions configures how external references are resolved
+type BundleOptions struct {
+	Location string                           // Location is the file path or url of the specification, relative references are resolved against it
+	Fetch    func(url string) ([]byte, error) // Fetch downloads remote references, remote references fail if it is nil
+}
+
+// BundledRef is an external reference that was copied into the specification
+type BundledRef struct {
+	Ref     string `json:"ref"`     // Ref is the absolute location of the external reference
+	Pointer string `json:"pointer"` // Pointer is the local reference that replaces it, empty if the content was inlined
+}
+
+// bundleSchemaKeys are the keywords whose values are schemas
+var bundleSchemaKeys = []string{"schema", "items", "not", "additionalProperties", "contains", "propertyNames", "if", "then", "else", "unevaluatedItems", "unevaluatedProperties", "contentSchema"}
+
+var componentNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Bundle copies all external references into the components of the specification, so it can be used without file or network access
+func Bundle(spec []byte, opts BundleOptions) ([]byte, []BundledRef, error) {
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := &bundler{root: root, opts: opts, documents: map[string]*yaml.Node{}, refs: map[string]string{}}
+	if err := b.bundleNode(root, opts.Location, nil); err != nil {
+		return nil, nil, err
+	}
+
+	output, err := renderNode(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output, b.bundled, nil
+}
+
+type bundler struct {
+	root      *yaml.Node
+	opts      BundleOptions
+	documents map[string]*yaml.Node // documents caches the parsed external documents by location
+	refs      map[string]string     // refs maps absolute external references to their local reference
+	bundled   []BundledRef
+}
+
+// bundleNode replaces all references below node, location is the document the node belongs to and tokens the position of node in it
+func (b *bundler) bundleNode(node *yaml.Node, location string, tokens []string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if ref := mappingValue(node, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode && (len(tokens) == 0 || tokens[len(tokens)-1] != "properties") {
+			return b.bundleRef(node, ref, location, tokens)
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := b.bundleNode(node.Content[i+1], location, append(slices.Clone(tokens), node.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if err := b.bundleNode(child, location, append(slices.Clone(tokens), fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// bundleRef resolves a single reference, local references of the specification are kept
+func (b *bundler) bundleRef(node *yaml.Node, ref *yaml.Node, location string, tokens []string) error {
+	refLocation, fragment, _ := strings.Cut(ref.Value, "#")
+	absolute := location
+	if refLocation != "" {
+		var err error
+		absolute, err = resolveLocation(location, refLocation)
+		if err != nil {
+			return fmt.Errorf("failed to resolve reference %s: %w", ref.Value, err)
+		}
+	}
+	if absolute == b.opts.Location {
+		ref.Value = "#" + fragment
+		return nil
+	}
+
+	key := absolute
+	if fragment != "" {
+		key += "#" + fragment
+	}
+	if local, ok := b.refs[key]; ok {
+		ref.Value = local
+		return nil
+	}
+
+	doc, err := b.document(absolute)
+	if err != nil {
+		return fmt.Errorf("failed to load reference %s: %w", key, err)
+	}
+	target, err := pointerGet(doc, fragment)
+	if err != nil {
+		return fmt.Errorf("failed to resolve reference %s: %w", key, err)
+	}
+	content := copyNode(target)
+
+	// components are referenced by name, everything else is inlined
+	componentTokens := b.componentGroup(tokens)
+	if componentTokens == nil {
+		*node = *content
+		b.bundled = append(b.bundled, BundledRef{Ref: key})
+		return b.bundleNode(node, absolute, tokens)
+	}
+
+	parent := b.root
+	for _, t := range componentTokens {
+		parent = mappingChild(parent, t)
+	}
+	name := b.componentName(parent, absolute, fragment, content)
+	local := "#" + jsonPointer(append(componentTokens, name)...)
+	b.refs[key] = local
+	ref.Value = local
+	b.bundled = append(b.bundled, BundledRef{Ref: key, Pointer: local[1:]})
+	if mappingValue(parent, name) != nil {
+		return nil // identical content was bundled from another location
+	}
+
+	// register the component before resolving its references, so recursive references point to it
+	mappingSet(parent, name, content)
+	return b.bundleNode(content, absolute, append(slices.Clone(componentTokens), name))
+}
+
+// componentGroup returns the location of the component group for a reference at the given position, nil if the content has to be inlined
+func (b *bundler) componentGroup(tokens []string) []string {
+	n := len(tokens)
+	group := ""
+	switch {
+	case n == 0:
+	case n >= 2 && slices.Contains([]string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}, tokens[n-2]):
+		group = "schemas"
+	case n >= 2 && slices.Contains([]string{"allOf", "oneOf", "anyOf", "prefixItems"}, tokens[n-2]):
+		group = "schemas"
+	case slices.Contains(bundleSchemaKeys, tokens[n-1]):
+		group = "schemas"
+	case n >= 2 && tokens[n-2] == "parameters":
+		group = "parameters"
+	case n >= 2 && tokens[n-2] == "responses":
+		group = "responses"
+	case tokens[n-1] == "requestBody":
+		group = "requestBodies"
+	case n >= 2 && slices.Contains([]string{"headers", "examples", "links", "callbacks"}, tokens[n-2]):
+		group = tokens[n-2]
+	}
+	if group == "" {
+		return nil
+	}
+
+	if isSwagger(b.root) {
+		switch group {
+		case "schemas":
+			return []string{"definitions"}
+		case "parameters", "responses":
+			return []string{group}
+		}
+		return nil
+	}
+	return []string{"components", group}
+}
+
+// componentName returns a name that is not used by a different component, identical components share the name
+func (b *bundler) componentName(group *yaml.Node, location string, fragment string, content *yaml.Node) string {
+	name := ""
+	if tokens, err := parsePointer(fragment); err == nil && len(tokens) > 0 {
+		name = tokens[len(tokens)-1]
+	} else {
+		name = strings.TrimSuffix(filepath.Base(location), filepath.Ext(location))
+	}
+	name = strings.Trim(componentNameRegex.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = "Bundled"
+	}
+
+	unique := name
+	for i := 2; ; i++ {
+		existing := mappingValue(group, unique)
+		if existing == nil || nodesEqual(existing, content) {
+			return unique
+		}
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// document loads and caches an external document
+func (b *bundler) document(location string) (*yaml.Node, error) {
+	if doc, ok := b.documents[location]; ok {
+		return doc, nil
+	}
+
+	var content []byte
+	var err error
+	if isRemote(location) {
+		if b.opts.Fetch == nil {
+			return nil, fmt.Errorf("remote references are not allowed")
+		}
+		content, err = b.opts.Fetch(location)
+	} else {
+		content, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, newParseError(err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("document %s is empty", location)
+	}
+	b.documents[location] = doc.Content[0]
+
+	return doc.Content[0], nil
+}
+
+// resolveLocation resolves a reference location relative to the document location
+func resolveLocation(base string, ref string) (string, error) {
+	if isRemote(ref) {
+		return ref, nil
+	}
+	if isRemote(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		refURL, err := url.Parse(ref)
+		if err != nil {
+			return "", err
+		}
+		return baseURL.ResolveReference(refURL).String(), nil
+	}
+	if filepath.IsAbs(ref) {
+		return filepath.Clean(ref), nil
+	}
+
+	return filepath.Join(filepath.Dir(base), ref), nil
+}
+
+// isRemote reports whether the location is a http or https url
+func isRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
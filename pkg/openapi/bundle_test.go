@@ -0,0 +1,165 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bundleSpec = `openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+paths:
+  /pets:
+    get:
+      parameters:
+        - $ref: 'common/parameters.yaml#/Limit'
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: 'pet.yaml'
+        default:
+          $ref: 'common/responses.yaml#/components/responses/Error'
+components:
+  schemas:
+    Error:
+      type: string
+`
+
+func writeBundleFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestBundle(t *testing.T) {
+	dir := writeBundleFiles(t, map[string]string{
+		"openapi.yaml": bundleSpec,
+		"pet.yaml": `type: object
+properties:
+  owner:
+    $ref: '#/definitions/Owner'
+  parent:
+    $ref: 'pet.yaml'
+definitions:
+  Owner:
+    type: object
+    properties:
+      name:
+        type: string
+`,
+		"common/parameters.yaml": `Limit:
+  name: limit
+  in: query
+  schema:
+    type: integer
+`,
+		"common/responses.yaml": `components:
+  responses:
+    Error:
+      description: error
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Error'
+  schemas:
+    Error:
+      type: object
+      properties:
+        message:
+          type: string
+`,
+	})
+	location := filepath.Join(dir, "openapi.yaml")
+
+	output, refs, err := Bundle([]byte(bundleSpec), BundleOptions{Location: location})
+	require.NoError(t, err)
+	assert.Len(t, refs, 5)
+
+	root, err := parseNode(output)
+	require.NoError(t, err)
+	get := mappingValue(mappingValue(mappingValue(root, "paths"), "/pets"), "get")
+	ref := func(pointer string) string {
+		node, err := pointerGet(root, pointer)
+		require.NoError(t, err)
+		return scalarValue(mappingValue(node, "$ref"))
+	}
+	assert.Equal(t, "#/components/parameters/Limit", scalarValue(mappingValue(mappingValue(get, "parameters").Content[0], "$ref")))
+	assert.Equal(t, "#/components/schemas/pet", ref("/paths/~1pets/get/responses/200/content/application~1json/schema"))
+	assert.Equal(t, "#/components/responses/Error", ref("/paths/~1pets/get/responses/default"))
+
+	// nested and recursive references point to the bundled components
+	assert.Equal(t, "#/components/schemas/Owner", ref("/components/schemas/pet/properties/owner"))
+	assert.Equal(t, "#/components/schemas/pet", ref("/components/schemas/pet/properties/parent"))
+
+	// a different schema with an existing name gets a suffix
+	assert.Equal(t, "string", scalarValue(mappingValue(mappingValue(mappingValue(mappingValue(root, "components"), "schemas"), "Error"), "type")))
+	assert.Equal(t, "#/components/schemas/Error2", ref("/components/responses/Error/content/application~1json/schema"))
+}
+
+func TestBundleRemote(t *testing.T) {
+	spec := []byte(`openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+paths: {}
+components:
+  schemas:
+    Pets:
+      type: array
+      items:
+        $ref: 'schemas/pet.yaml#/Pet'
+`)
+
+	// remote references are only resolved with a fetcher
+	_, _, err := Bundle(spec, BundleOptions{Location: "https://example.com/api/openapi.yaml"})
+	assert.ErrorContains(t, err, "remote references are not allowed")
+
+	var fetched []string
+	_, refs, err := Bundle(spec, BundleOptions{
+		Location: "https://example.com/api/openapi.yaml",
+		Fetch: func(url string) ([]byte, error) {
+			fetched = append(fetched, url)
+			return []byte("Pet:\n  type: object\n  properties:\n    name:\n      type: string\n"), nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/api/schemas/pet.yaml"}, fetched)
+	assert.Equal(t, []BundledRef{{Ref: "https://example.com/api/schemas/pet.yaml#/Pet", Pointer: "/components/schemas/Pet"}}, refs)
+}
+
+func TestBundleSwagger(t *testing.T) {
+	dir := writeBundleFiles(t, map[string]string{
+		"pet.yaml": "type: object\n",
+	})
+	spec := []byte(`swagger: "2.0"
+info:
+  title: A
+  version: "1.0.0"
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          schema:
+            $ref: 'pet.yaml'
+`)
+
+	output, _, err := Bundle(spec, BundleOptions{Location: filepath.Join(dir, "swagger.yaml")})
+	require.NoError(t, err)
+	root, err := parseNode(output)
+	require.NoError(t, err)
+	assert.NotNil(t, mappingValue(mappingValue(root, "definitions"), "pet"))
+}
@@ -0,0 +1,216 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CanonicalOptions configures the canonical output
+type CanonicalOptions struct {
+	JSON            bool // JSON renders the specification as JSON instead of YAML
+	Indent          int  // Indent is the number of spaces used for indentation, defaults to 2
+	TrailingNewline bool // TrailingNewline ends the output with a newline
+}
+
+// field order of the openapi and swagger objects, keys that are not listed are sorted alphabetically after the known keys
+var (
+	rootFieldOrder       = []string{"openapi", "swagger", "info", "jsonSchemaDialect", "host", "basePath", "schemes", "consumes", "produces", "servers", "security", "tags", "externalDocs", "paths", "webhooks", "components", "definitions", "parameters", "responses", "securityDefinitions"}
+	infoFieldOrder       = []string{"title", "summary", "description", "termsOfService", "contact", "license", "version"}
+	pathItemFieldOrder   = append([]string{"$ref", "summary", "description", "servers", "parameters"}, httpMethods...)
+	operationFieldOrder  = []string{"tags", "summary", "description", "externalDocs", "operationId", "consumes", "produces", "schemes", "parameters", "requestBody", "responses", "callbacks", "deprecated", "security", "servers"}
+	componentsFieldOrder = []string{"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "securitySchemes", "links", "callbacks", "pathItems"}
+)
+
+// Canonicalize renders the specification with a stable key order and formatting, the result does not depend on the key order or quoting of the input
+func Canonicalize(spec []byte, opts CanonicalOptions) ([]byte, error) {
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Indent <= 0 {
+		opts.Indent = 2
+	}
+
+	normalizeStyle(root)
+	orderKeys(root, rootFieldOrder)
+	orderKeys(mappingValue(root, "info"), infoFieldOrder)
+	for _, key := range []string{"paths", "webhooks"} {
+		paths := mappingValue(root, key)
+		orderKeys(paths, nil)
+		for _, p := range mappingKeys(paths) {
+			orderPathItem(mappingValue(paths, p))
+		}
+	}
+	components := mappingValue(root, "components")
+	orderKeys(components, componentsFieldOrder)
+	for _, componentType := range mappingKeys(components) {
+		if !strings.HasPrefix(componentType, "x-") {
+			orderKeys(mappingValue(components, componentType), nil)
+		}
+	}
+	for _, key := range swaggerComponents {
+		orderKeys(mappingValue(root, key), nil)
+	}
+
+	var output []byte
+	if opts.JSON {
+		var buf bytes.Buffer
+		writeJSON(&buf, root, opts.Indent, 0)
+		output = buf.Bytes()
+	} else {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(opts.Indent)
+		if err := enc.Encode(root); err != nil {
+			return nil, &RenderError{Err: err}
+		}
+		if err := enc.Close(); err != nil {
+			return nil, &RenderError{Err: err}
+		}
+		output = buf.Bytes()
+	}
+
+	output = bytes.TrimRight(output, "\n")
+	if opts.TrailingNewline {
+		output = append(output, '\n')
+	}
+
+	return output, nil
+}
+
+// orderPathItem orders the fields of a path item, its operations and responses
+func orderPathItem(item *yaml.Node) {
+	orderKeys(item, pathItemFieldOrder)
+	for _, method := range httpMethods {
+		op := mappingValue(item, method)
+		orderKeys(op, operationFieldOrder)
+		orderKeys(mappingValue(op, "responses"), nil) // status codes sort before default
+	}
+}
+
+// orderKeys sorts the keys of a mapping, keys in fieldOrder come first in the given order, all other keys are sorted alphabetically with extensions last
+func orderKeys(node *yaml.Node, fieldOrder []string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+	rank := func(key string) int {
+		if i := slices.Index(fieldOrder, key); i >= 0 {
+			return i
+		}
+		if strings.HasPrefix(key, "x-") {
+			return len(fieldOrder) + 1
+		}
+		return len(fieldOrder)
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int {
+		if ra, rb := rank(a.key.Value), rank(b.key.Value); ra != rb {
+			return ra - rb
+		}
+		return strings.Compare(a.key.Value, b.key.Value)
+	})
+
+	node.Content = node.Content[:0]
+	for _, p := range pairs {
+		node.Content = append(node.Content, p.key, p.value)
+	}
+}
+
+// normalizeStyle resets the formatting of all nodes, so quoting and flow style are chosen by the encoder, mapping keys are always strings
+func normalizeStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content); i += 2 {
+			node.Content[i].Tag = "!!str"
+		}
+	}
+	for _, child := range node.Content {
+		normalizeStyle(child)
+	}
+}
+
+// writeJSON renders a node as indented JSON, keeping the key order of mappings
+func writeJSON(buf *bytes.Buffer, node *yaml.Node, indent int, depth int) {
+	newline := func(d int) {
+		buf.WriteByte('\n')
+		buf.WriteString(strings.Repeat(" ", indent*d))
+	}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		writeJSON(buf, node.Alias, indent, depth)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(depth + 1)
+			writeJSONString(buf, node.Content[i].Value)
+			buf.WriteString(": ")
+			writeJSON(buf, node.Content[i+1], indent, depth+1)
+		}
+		newline(depth)
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(depth + 1)
+			writeJSON(buf, child, indent, depth+1)
+		}
+		newline(depth)
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			buf.WriteString("null")
+		case "!!bool":
+			var b bool
+			if err := node.Decode(&b); err == nil && b {
+				buf.WriteString("true")
+			} else {
+				buf.WriteString("false")
+			}
+		case "!!int", "!!float":
+			var v any
+			if err := node.Decode(&v); err == nil {
+				if encoded, err := json.Marshal(v); err == nil {
+					buf.Write(encoded)
+					return
+				}
+			}
+			writeJSONString(buf, node.Value)
+		default:
+			writeJSONString(buf, node.Value)
+		}
+	}
+}
+
+// writeJSONString writes a JSON string without escaping html characters
+func writeJSONString(buf *bytes.Buffer, value string) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(value)
+	buf.Write(bytes.TrimRight(b.Bytes(), "\n"))
+}
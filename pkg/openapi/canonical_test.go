@@ -0,0 +1,84 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const canonicalSpec = `components:
+  schemas:
+    Pet: {type: object, properties: {name: {type: string}, id: {type: integer}}}
+    Error:
+      type: object
+paths:
+  /pets:
+    post:
+      responses:
+        default: {description: error}
+        201: {description: created}
+      operationId: createPet
+  /owners:
+    get:
+      responses:
+        '200':
+          description: "ok"
+info:
+  version: "1.0"
+  title: A
+openapi: 3.0.3
+`
+
+const canonicalYAML = `openapi: 3.0.3
+info:
+    title: A
+    version: "1.0"
+paths:
+    /owners:
+        get:
+            responses:
+                "200":
+                    description: ok
+    /pets:
+        post:
+            operationId: createPet
+            responses:
+                "201":
+                    description: created
+                default:
+                    description: error
+components:
+    schemas:
+        Error:
+            type: object
+        Pet:
+            type: object
+            properties:
+                name:
+                    type: string
+                id:
+                    type: integer
+`
+
+func TestCanonicalize(t *testing.T) {
+	output, err := Canonicalize([]byte(canonicalSpec), CanonicalOptions{Indent: 4, TrailingNewline: true})
+	assert.NoError(t, err)
+	assert.Equal(t, canonicalYAML, string(output))
+
+	again, err := Canonicalize(output, CanonicalOptions{Indent: 4, TrailingNewline: true})
+	assert.NoError(t, err)
+	assert.Equal(t, string(output), string(again))
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	output, err := Canonicalize([]byte(canonicalSpec), CanonicalOptions{JSON: true})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"openapi":"3.0.3","info":{"title":"A","version":"1.0"},"paths":{"/owners":{"get":{"responses":{"200":{"description":"ok"}}}},"/pets":{"post":{"operationId":"createPet","responses":{"201":{"description":"created"},"default":{"description":"error"}}}}},"components":{"schemas":{"Error":{"type":"object"},"Pet":{"type":"object","properties":{"name":{"type":"string"},"id":{"type":"integer"}}}}}}`, string(output))
+	assert.True(t, strings.HasPrefix(string(output), "{\n  \"openapi\": \"3.0.3\",\n  \"info\": {\n    \"title\""))
+	assert.NotEqual(t, byte('\n'), output[len(output)-1])
+
+	again, err := Canonicalize(output, CanonicalOptions{JSON: true})
+	assert.NoError(t, err)
+	assert.Equal(t, string(output), string(again))
+}
@@ -0,0 +1,290 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// ConversionLoss describes information that could not be represented in the target openapi version
+type ConversionLoss struct {
+	Pointer string `json:"pointer"` // Pointer is the JSON pointer of the affected location
+	Message string `json:"message"` // Message describes what was lost
+}
+
+// openAPIVersions maps the versioned spec types to the version that is written into the document
+var openAPIVersions = map[config.SpecType]string{
+	config.SpecTypeOpenAPI30: "3.0.3",
+	config.SpecTypeOpenAPI31: "3.1.0",
+}
+
+// schemaOnlyKeywords31 are json schema keywords that are not supported by openapi 3.0
+var schemaOnlyKeywords31 = []string{"$schema", "$id", "$anchor", "$dynamicAnchor", "$dynamicRef", "$defs", "$comment", "prefixItems", "contains", "minContains", "maxContains", "patternProperties", "propertyNames", "dependentSchemas", "dependentRequired", "unevaluatedProperties", "unevaluatedItems", "if", "then", "else", "contentEncoding", "contentMediaType", "contentSchema"}
+
+// ConvertOpenAPIVersion converts an openapi 3 specification to the version of the spec type, other spec types are returned unchanged
+func ConvertOpenAPIVersion(spec []byte, specType config.SpecType) ([]byte, []ConversionLoss, error) {
+	version, ok := openAPIVersions[specType]
+	if !ok {
+		return spec, nil, nil
+	}
+
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse specification: %w", err)
+	}
+	if mappingValue(root, "openapi") == nil {
+		return nil, nil, fmt.Errorf("specification is not an openapi 3 document")
+	}
+
+	// schemas are converted even if the document already declares the target version, merged sources may mix versions
+	var losses []ConversionLoss
+	mappingSet(root, "openapi", stringNode(version))
+	for _, s := range schemaNodes(root) {
+		if specType == config.SpecTypeOpenAPI31 {
+			losses = append(losses, upgradeSchema(s.node, s.pointer)...)
+		} else {
+			losses = append(losses, downgradeSchema(s.node, s.pointer)...)
+		}
+	}
+	if specType == config.SpecTypeOpenAPI30 {
+		losses = append(losses, downgradeDocument(root)...)
+	}
+
+	output, err := renderNode(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render specification: %w", err)
+	}
+
+	return output, losses, nil
+}
+
+// upgradeSchema converts a single openapi 3.0 schema to openapi 3.1
+func upgradeSchema(schema *yaml.Node, pointer string) []ConversionLoss {
+	var losses []ConversionLoss
+
+	// nullable becomes a type array
+	if nullable := mappingValue(schema, "nullable"); nullable != nil {
+		mappingDelete(schema, "nullable")
+		if nullable.Value == "true" {
+			typ := mappingValue(schema, "type")
+			switch {
+			case typ != nil && typ.Kind == yaml.ScalarNode:
+				mappingSet(schema, "type", stringSequence([]string{typ.Value, "null"}))
+			case typ != nil && typ.Kind == yaml.SequenceNode:
+				if !slices.Contains(sequenceValues(typ), "null") {
+					typ.Content = append(typ.Content, stringNode("null"))
+				}
+			default:
+				losses = append(losses, ConversionLoss{Pointer: pointer, Message: "nullable without type was dropped"})
+			}
+		}
+	}
+
+	// boolean exclusive bounds become numeric bounds
+	for _, bound := range [][2]string{{"exclusiveMinimum", "minimum"}, {"exclusiveMaximum", "maximum"}} {
+		exclusive := mappingValue(schema, bound[0])
+		if exclusive == nil || exclusive.ShortTag() != "!!bool" {
+			continue
+		}
+		limit := mappingValue(schema, bound[1])
+		if exclusive.Value == "true" && limit != nil {
+			mappingSet(schema, bound[0], limit)
+			mappingDelete(schema, bound[1])
+		} else {
+			mappingDelete(schema, bound[0])
+		}
+	}
+
+	// example becomes examples
+	if example := mappingValue(schema, "example"); example != nil {
+		mappingDelete(schema, "example")
+		if mappingValue(schema, "examples") == nil {
+			mappingSet(schema, "examples", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{example}})
+		}
+	}
+
+	return losses
+}
+
+// downgradeSchema converts a single openapi 3.1 schema to openapi 3.0
+func downgradeSchema(schema *yaml.Node, pointer string) []ConversionLoss {
+	var losses []ConversionLoss
+
+	// type arrays become nullable
+	if typ := mappingValue(schema, "type"); typ != nil && typ.Kind == yaml.SequenceNode {
+		types := sequenceValues(typ)
+		nullable := slices.Contains(types, "null")
+		types = slices.DeleteFunc(types, func(t string) bool { return t == "null" })
+		switch {
+		case len(types) == 0:
+			mappingDelete(schema, "type")
+		case len(types) == 1:
+			mappingSet(schema, "type", stringNode(types[0]))
+		default:
+			mappingSet(schema, "type", stringNode(types[0]))
+			losses = append(losses, ConversionLoss{Pointer: pointer, Message: fmt.Sprintf("type [%s] was reduced to %s", strings.Join(types, ", "), types[0])})
+		}
+		if nullable {
+			mappingSet(schema, "nullable", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		}
+	} else if typ != nil && typ.Value == "null" {
+		mappingDelete(schema, "type")
+		mappingSet(schema, "nullable", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		losses = append(losses, ConversionLoss{Pointer: pointer, Message: "type null was replaced by nullable"})
+	}
+
+	// a null alternative in oneOf or anyOf becomes nullable
+	for _, key := range []string{"oneOf", "anyOf"} {
+		alternatives := mappingValue(schema, key)
+		if alternatives == nil || alternatives.Kind != yaml.SequenceNode {
+			continue
+		}
+		count := len(alternatives.Content)
+		alternatives.Content = slices.DeleteFunc(alternatives.Content, func(n *yaml.Node) bool {
+			return len(n.Content) == 2 && scalarValue(mappingValue(n, "type")) == "null"
+		})
+		if len(alternatives.Content) != count {
+			mappingSet(schema, "nullable", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		}
+	}
+
+	// numeric exclusive bounds become boolean bounds
+	for _, bound := range [][2]string{{"exclusiveMinimum", "minimum"}, {"exclusiveMaximum", "maximum"}} {
+		exclusive := mappingValue(schema, bound[0])
+		if exclusive == nil || exclusive.ShortTag() == "!!bool" {
+			continue
+		}
+		mappingSet(schema, bound[1], exclusive)
+		mappingSet(schema, bound[0], &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+	}
+
+	// examples become example
+	if examples := mappingValue(schema, "examples"); examples != nil && examples.Kind == yaml.SequenceNode {
+		mappingDelete(schema, "examples")
+		if len(examples.Content) > 0 && mappingValue(schema, "example") == nil {
+			mappingSet(schema, "example", examples.Content[0])
+		}
+		if len(examples.Content) > 1 {
+			losses = append(losses, ConversionLoss{Pointer: pointer + "/examples", Message: fmt.Sprintf("only the first of %d examples was kept", len(examples.Content))})
+		}
+	}
+
+	// const becomes a single value enum
+	if constant := mappingValue(schema, "const"); constant != nil {
+		mappingDelete(schema, "const")
+		mappingSet(schema, "enum", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{constant}})
+	}
+
+	for _, keyword := range schemaOnlyKeywords31 {
+		if mappingDelete(schema, keyword) {
+			losses = append(losses, ConversionLoss{Pointer: pointer + jsonPointer(keyword), Message: fmt.Sprintf("%s is not supported by openapi 3.0", keyword)})
+		}
+	}
+
+	return losses
+}
+
+// downgradeDocument removes document level features that are not supported by openapi 3.0
+func downgradeDocument(root *yaml.Node) []ConversionLoss {
+	var losses []ConversionLoss
+	remove := func(parent *yaml.Node, pointer string, key string) {
+		if mappingDelete(parent, key) {
+			losses = append(losses, ConversionLoss{Pointer: pointer + jsonPointer(key), Message: fmt.Sprintf("%s is not supported by openapi 3.0", key)})
+		}
+	}
+
+	remove(root, "", "webhooks")
+	remove(root, "", "jsonSchemaDialect")
+	info := mappingValue(root, "info")
+	remove(info, "/info", "summary")
+	remove(mappingValue(info, "license"), "/info/license", "identifier")
+	components := mappingValue(root, "components")
+	remove(components, "/components", "pathItems")
+	if components != nil && len(components.Content) == 0 {
+		mappingDelete(root, "components")
+	}
+
+	// paths are required in openapi 3.0
+	if mappingValue(root, "paths") == nil {
+		mappingSet(root, "paths", mappingNode())
+	}
+
+	return losses
+}
+
+// schemaLocation is a schema node and its JSON pointer
+type schemaLocation struct {
+	node    *yaml.Node
+	pointer string
+}
+
+// schemaNodes returns all schema objects of the document, nested schemas are returned after their parent
+func schemaNodes(root *yaml.Node) []schemaLocation {
+	var result []schemaLocation
+	visited := map[*yaml.Node]bool{}
+
+	var visitSchema func(node *yaml.Node, pointer string)
+	visitSchema = func(node *yaml.Node, pointer string) {
+		if node == nil || node.Kind != yaml.MappingNode || visited[node] {
+			return
+		}
+		visited[node] = true
+		result = append(result, schemaLocation{node: node, pointer: pointer})
+
+		for _, key := range mappingKeys(node) {
+			value := mappingValue(node, key)
+			p := pointer + jsonPointer(key)
+			switch key {
+			case "properties", "patternProperties", "$defs", "dependentSchemas":
+				for _, name := range mappingKeys(value) {
+					visitSchema(mappingValue(value, name), p+jsonPointer(name))
+				}
+			case "items", "additionalProperties", "not", "contains", "propertyNames", "if", "then", "else", "unevaluatedItems", "unevaluatedProperties", "contentSchema":
+				if value.Kind == yaml.SequenceNode {
+					for i, item := range value.Content {
+						visitSchema(item, p+jsonPointer(fmt.Sprint(i)))
+					}
+				} else {
+					visitSchema(value, p)
+				}
+			case "allOf", "oneOf", "anyOf", "prefixItems":
+				for i, item := range value.Content {
+					visitSchema(item, p+jsonPointer(fmt.Sprint(i)))
+				}
+			}
+		}
+	}
+
+	// schemas are located in components and below every schema key outside of examples
+	var walk func(node *yaml.Node, pointer string)
+	walk = func(node *yaml.Node, pointer string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for _, key := range mappingKeys(node) {
+				value := mappingValue(node, key)
+				p := pointer + jsonPointer(key)
+				switch {
+				case key == "example" || key == "examples" || strings.HasPrefix(key, "x-"):
+				case key == "schema":
+					visitSchema(value, p)
+				case pointer == "/components" && key == "schemas":
+					for _, name := range mappingKeys(value) {
+						visitSchema(mappingValue(value, name), p+jsonPointer(name))
+					}
+				default:
+					walk(value, p)
+				}
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(item, pointer+jsonPointer(fmt.Sprint(i)))
+			}
+		}
+	}
+	walk(root, "")
+
+	return result
+}
@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const convertSpec30 = `openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          example: 10
+          schema:
+            type: integer
+            minimum: 0
+            exclusiveMinimum: true
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          nullable: true
+          example: Rex
+`
+
+const convertSpec31 = `openapi: 3.1.0
+info:
+  title: A
+  summary: pets
+  version: "1.0.0"
+webhooks:
+  newPet:
+    post:
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: [string, "null"]
+          examples: [Rex, Max]
+        age:
+          type: [integer, string]
+          exclusiveMaximum: 100
+        kind:
+          const: dog
+        tags:
+          type: array
+          prefixItems:
+            - type: string
+`
+
+func TestConvertOpenAPIVersionUpgrade(t *testing.T) {
+	output, losses, err := ConvertOpenAPIVersion([]byte(convertSpec30), config.SpecTypeOpenAPI31)
+	assert.NoError(t, err)
+	assert.Empty(t, losses)
+
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	assert.Equal(t, "3.1.0", mappingValue(root, "openapi").Value)
+
+	name, err := pointerGet(root, "/components/schemas/Pet/properties/name")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"string", "null"}, sequenceValues(mappingValue(name, "type")))
+	assert.Nil(t, mappingValue(name, "nullable"))
+	assert.Equal(t, []string{"Rex"}, sequenceValues(mappingValue(name, "examples")))
+
+	param, err := pointerGet(root, "/paths/~1pets/get/parameters/0")
+	assert.NoError(t, err)
+	assert.Equal(t, "10", mappingValue(param, "example").Value, "parameter examples are not schema examples")
+	schema := mappingValue(param, "schema")
+	assert.Equal(t, "0", mappingValue(schema, "exclusiveMinimum").Value)
+	assert.Nil(t, mappingValue(schema, "minimum"))
+}
+
+func TestConvertOpenAPIVersionDowngrade(t *testing.T) {
+	output, losses, err := ConvertOpenAPIVersion([]byte(convertSpec31), config.SpecTypeOpenAPI30)
+	assert.NoError(t, err)
+	assert.Equal(t, []ConversionLoss{
+		{Pointer: "/components/schemas/Pet/properties/name/examples", Message: "only the first of 2 examples was kept"},
+		{Pointer: "/components/schemas/Pet/properties/age", Message: "type [integer, string] was reduced to integer"},
+		{Pointer: "/components/schemas/Pet/properties/tags/prefixItems", Message: "prefixItems is not supported by openapi 3.0"},
+		{Pointer: "/webhooks", Message: "webhooks is not supported by openapi 3.0"},
+		{Pointer: "/info/summary", Message: "summary is not supported by openapi 3.0"},
+	}, losses)
+
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	assert.Equal(t, "3.0.3", mappingValue(root, "openapi").Value)
+	assert.NotNil(t, mappingValue(root, "paths"))
+
+	properties, err := pointerGet(root, "/components/schemas/Pet/properties")
+	assert.NoError(t, err)
+	name := mappingValue(properties, "name")
+	assert.Equal(t, "string", mappingValue(name, "type").Value)
+	assert.Equal(t, "true", mappingValue(name, "nullable").Value)
+	assert.Equal(t, "Rex", mappingValue(name, "example").Value)
+	age := mappingValue(properties, "age")
+	assert.Equal(t, "100", mappingValue(age, "maximum").Value)
+	assert.Equal(t, "true", mappingValue(age, "exclusiveMaximum").Value)
+	assert.Equal(t, []string{"dog"}, sequenceValues(mappingValue(mappingValue(properties, "kind"), "enum")))
+}
+
+func TestConvertOpenAPIVersionKeep(t *testing.T) {
+	output, losses, err := ConvertOpenAPIVersion([]byte(convertSpec31), config.SpecTypeOpenAPI3)
+	assert.NoError(t, err)
+	assert.Empty(t, losses)
+	assert.Equal(t, convertSpec31, string(output))
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/primelib/primecodegen-app/pkg/config"
+)
+
+// customizeServers replaces the servers, variables of upstream servers with the same url are kept unless overridden
+func customizeServers(upstream []*v3.Server, servers []config.CustomizationServer) []*v3.Server {
+	var result []*v3.Server
+	for _, server := range servers {
+		variables := orderedmap.New[string, *v3.ServerVariable]()
+		for _, u := range upstream {
+			if u.URL == server.URL && u.Variables != nil {
+				for name, variable := range u.Variables.FromOldest() {
+					variables.Set(name, variable)
+				}
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(server.Variables)) {
+			override := server.Variables[name]
+			variable, ok := variables.Get(name)
+			if !ok {
+				variable = &v3.ServerVariable{}
+				variables.Set(name, variable)
+			}
+			if len(override.Enum) > 0 {
+				variable.Enum = override.Enum
+			}
+			if override.Default != "" {
+				variable.Default = override.Default
+			}
+			if override.Description != "" {
+				variable.Description = override.Description
+			}
+		}
+
+		s := &v3.Server{
+			URL:         server.URL,
+			Description: server.Description,
+		}
+		if variables.Len() > 0 {
+			s.Variables = variables
+		}
+		result = append(result, s)
+	}
+
+	return result
+}
+
+// customizeSecuritySchemes adds or replaces the security schemes in the components
+func customizeSecuritySchemes(model *v3.Document, schemes map[string]config.CustomizationSecurityScheme) {
+	if model.Components == nil {
+		model.Components = &v3.Components{}
+	}
+	if model.Components.SecuritySchemes == nil {
+		model.Components.SecuritySchemes = orderedmap.New[string, *v3.SecurityScheme]()
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(schemes)) {
+		scheme := schemes[name]
+		s := &v3.SecurityScheme{
+			Type:             scheme.Type,
+			Description:      scheme.Description,
+			Name:             scheme.Name,
+			In:               scheme.In,
+			Scheme:           scheme.Scheme,
+			BearerFormat:     scheme.BearerFormat,
+			OpenIdConnectUrl: scheme.OpenIdConnectURL,
+		}
+		if scheme.Flows.Implicit != nil || scheme.Flows.Password != nil || scheme.Flows.ClientCredentials != nil || scheme.Flows.AuthorizationCode != nil {
+			s.Flows = &v3.OAuthFlows{
+				Implicit:          oauthFlow(scheme.Flows.Implicit),
+				Password:          oauthFlow(scheme.Flows.Password),
+				ClientCredentials: oauthFlow(scheme.Flows.ClientCredentials),
+				AuthorizationCode: oauthFlow(scheme.Flows.AuthorizationCode),
+			}
+		}
+		model.Components.SecuritySchemes.Set(name, s)
+	}
+}
+
+func oauthFlow(flow *config.CustomizationOAuthFlow) *v3.OAuthFlow {
+	if flow == nil {
+		return nil
+	}
+
+	scopes := orderedmap.New[string, string]()
+	for _, scope := range slices.Sorted(maps.Keys(flow.Scopes)) {
+		scopes.Set(scope, flow.Scopes[scope])
+	}
+
+	return &v3.OAuthFlow{
+		AuthorizationUrl: flow.AuthorizationURL,
+		TokenUrl:         flow.TokenURL,
+		RefreshUrl:       flow.RefreshURL,
+		Scopes:           scopes,
+	}
+}
+
+// securityRequirements converts the configured requirements, an empty requirement makes authentication optional
+func securityRequirements(security []map[string][]string) []*base.SecurityRequirement {
+	var result []*base.SecurityRequirement
+	for _, requirement := range security {
+		r := &base.SecurityRequirement{
+			Requirements:             orderedmap.New[string, []string](),
+			ContainsEmptyRequirement: len(requirement) == 0,
+		}
+		for _, name := range slices.Sorted(maps.Keys(requirement)) {
+			scopes := requirement[name]
+			if scopes == nil {
+				scopes = []string{}
+			}
+			r.Requirements.Set(name, scopes)
+		}
+		result = append(result, r)
+	}
+
+	return result
+}
@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const customizeSpec = `openapi: 3.0.3
+info:
+  title: Tenants
+  version: "1.0.0"
+servers:
+  - url: https://{tenant}.example.com/{version}
+    variables:
+      tenant:
+        default: demo
+      version:
+        default: v1
+        enum: [v1]
+paths: {}
+`
+
+func TestPatchDocumentServersAndSecurity(t *testing.T) {
+	doc, err := OpenDocument([]byte(customizeSpec), nil)
+	assert.NoError(t, err)
+	specInfo := doc.GetSpecInfo()
+	doc, err = PatchDocument(doc, specInfo.SpecType, specInfo.SpecFormat, specInfo.VersionNumeric, config.Customization{
+		Servers: []config.CustomizationServer{{
+			URL:       "https://{tenant}.example.com/{version}",
+			Variables: map[string]config.CustomizationServerVariable{"version": {Default: "v2", Enum: []string{"v1", "v2"}}},
+		}},
+		SecuritySchemes: map[string]config.CustomizationSecurityScheme{
+			"bearer": {Type: "http", Scheme: "bearer"},
+			"oauth": {Type: "oauth2", Flows: config.CustomizationOAuthFlows{
+				ClientCredentials: &config.CustomizationOAuthFlow{TokenURL: "https://auth.example.com/token", Scopes: map[string]string{"read": "read access"}},
+			}},
+		},
+		Security: []map[string][]string{{"bearer": nil}, {"oauth": {"read"}}},
+	})
+	assert.NoError(t, err)
+	output, err := doc.Render()
+	assert.NoError(t, err)
+
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	variables := mappingValue(mappingValue(root, "servers").Content[0], "variables")
+	assert.Equal(t, "demo", mappingValue(mappingValue(variables, "tenant"), "default").Value)
+	assert.Equal(t, "v2", mappingValue(mappingValue(variables, "version"), "default").Value)
+	assert.Len(t, mappingValue(mappingValue(variables, "version"), "enum").Content, 2)
+
+	schemes := mappingValue(mappingValue(root, "components"), "securitySchemes")
+	assert.Equal(t, []string{"bearer", "oauth"}, mappingKeys(schemes))
+	tokenURL := mappingValue(mappingValue(mappingValue(mappingValue(schemes, "oauth"), "flows"), "clientCredentials"), "tokenUrl")
+	assert.Equal(t, "https://auth.example.com/token", tokenURL.Value)
+
+	security := mappingValue(root, "security")
+	assert.Len(t, security.Content, 2)
+	assert.Equal(t, []string{"bearer"}, mappingKeys(security.Content[0]))
+	assert.Equal(t, "read", mappingValue(security.Content[1], "oauth").Content[0].Value)
+}
@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+)
+
+// ErrorLocation is a position in the specification that caused an error, messages of the underlying errors usually contain the position already
+type ErrorLocation struct {
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// ParseError is returned if a specification is not valid yaml or json, or not a supported document
+type ParseError struct {
+	Locations []ErrorLocation
+	Err       error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error: %s", joinLocations(e.Locations))
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ModelError is returned if the document model can not be built from a specification, e.g. because of unresolvable references
+type ModelError struct {
+	Locations []ErrorLocation
+	Errs      []error
+}
+
+func (e *ModelError) Error() string {
+	return fmt.Sprintf("model error: %s", joinLocations(e.Locations))
+}
+
+func (e *ModelError) Unwrap() []error {
+	return e.Errs
+}
+
+// RenderError is returned if a document can not be rendered
+type RenderError struct {
+	Err error
+}
+
+func (e *RenderError) Error() string {
+	return fmt.Sprintf("render error: %s", e.Err.Error())
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorLocations returns the document locations of a parse or model error
+func ErrorLocations(err error) []ErrorLocation {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.Locations
+	}
+	var modelErr *ModelError
+	if errors.As(err, &modelErr) {
+		return modelErr.Locations
+	}
+
+	return nil
+}
+
+// newParseError creates a parse error, the line is extracted from yaml error messages
+func newParseError(err error) *ParseError {
+	return &ParseError{Locations: []ErrorLocation{messageLocation(err.Error())}, Err: err}
+}
+
+// newModelError creates a model error with the locations of all resolver errors
+func newModelError(errs []error) *ModelError {
+	var locations []ErrorLocation
+	for _, err := range flattenErrors(errs) {
+		var resolvingErr *index.ResolvingError
+		if errors.As(err, &resolvingErr) && resolvingErr.Node != nil {
+			locations = append(locations, ErrorLocation{
+				Line:    resolvingErr.Node.Line,
+				Column:  resolvingErr.Node.Column,
+				Path:    resolvingErr.Path,
+				Message: resolvingErr.Error(),
+			})
+			continue
+		}
+		locations = append(locations, messageLocation(err.Error()))
+	}
+
+	return &ModelError{Locations: locations, Errs: errs}
+}
+
+// flattenErrors expands joined errors
+func flattenErrors(errs []error) []error {
+	var result []error
+	for _, err := range errs {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			result = append(result, flattenErrors(joined.Unwrap())...)
+		} else if err != nil {
+			result = append(result, err)
+		}
+	}
+
+	return result
+}
+
+var lineRegex = regexp.MustCompile(`line (\d+)(?::(\d+))?`)
+
+// messageLocation extracts the line and column from an error message
+func messageLocation(message string) ErrorLocation {
+	location := ErrorLocation{Message: message}
+	if match := lineRegex.FindStringSubmatch(message); match != nil {
+		location.Line, _ = strconv.Atoi(match[1])
+		location.Column, _ = strconv.Atoi(match[2])
+	}
+
+	return location
+}
+
+// joinLocations formats the locations as a single line
+func joinLocations(locations []ErrorLocation) string {
+	messages := make([]string, 0, len(locations))
+	for _, l := range locations {
+		messages = append(messages, l.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
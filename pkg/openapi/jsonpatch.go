@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// errTargetNotFound is returned if a JSON pointer references a location that does not exist
+var errTargetNotFound = errors.New("target not found")
+
+// errFromNotFound is returned with errTargetNotFound if the from location of a move or copy does not exist
+var errFromNotFound = errors.New("from location not found")
+
+// jsonPatchOperation is a single RFC 6902 JSON Patch operation
+type jsonPatchOperation struct {
+	Op    string    `yaml:"op"`
+	Path  string    `yaml:"path"`
+	From  string    `yaml:"from"`
+	Value yaml.Node `yaml:"value"`
+}
+
+// applyJSONPatch applies a RFC 6902 JSON Patch. Operations whose target does not exist are skipped and reported as missed,
+// a failed test or a malformed operation aborts the patch and leaves the document untouched.
+func applyJSONPatch(root *yaml.Node, patch Patch) (PatchReport, error) {
+	report := PatchReport{Patch: patch.Name}
+
+	var operations []jsonPatchOperation
+	if err := yaml.Unmarshal(patch.Content, &operations); err != nil {
+		return report, &PatchError{Patch: patch.Name, Err: fmt.Errorf("invalid json patch: %w", err)}
+	}
+
+	doc := copyNode(root)
+	for _, op := range operations {
+		err := applyJSONPatchOperation(doc, op)
+		if errors.Is(err, errTargetNotFound) && op.Op != "test" {
+			missed := op.Path
+			if errors.Is(err, errFromNotFound) {
+				missed = op.From
+			}
+			log.Debug().Err(err).Str("patch", patch.Name).Str("op", op.Op).Str("path", missed).Msg("skipping json patch operation, the target does not exist")
+			report.Missed = append(report.Missed, missed)
+			continue
+		}
+		if err != nil {
+			return PatchReport{Patch: patch.Name}, &PatchError{Patch: patch.Name, Pointer: op.Path, Err: fmt.Errorf("%s operation failed: %w", op.Op, err)}
+		}
+		report.Touched = append(report.Touched, op.Path)
+	}
+	*root = *doc
+
+	return report, nil
+}
+
+func applyJSONPatchOperation(root *yaml.Node, op jsonPatchOperation) error {
+	switch op.Op {
+	case "add":
+		if op.Value.IsZero() {
+			return fmt.Errorf("add requires a value")
+		}
+		return pointerAdd(root, op.Path, copyNode(&op.Value))
+	case "remove":
+		_, err := pointerRemove(root, op.Path)
+		return err
+	case "replace":
+		if op.Value.IsZero() {
+			return fmt.Errorf("replace requires a value")
+		}
+		value, err := pointerGet(root, op.Path)
+		if err != nil {
+			return err
+		}
+		*value = *copyNode(&op.Value)
+		return nil
+	case "move":
+		value, err := pointerRemove(root, op.From)
+		if err != nil {
+			return fromError(err)
+		}
+		// the value is already removed, a missing target aborts the patch instead of dropping the value
+		if err := pointerAdd(root, op.Path, value); err != nil {
+			return fmt.Errorf("failed to add the value moved from %s: %s", op.From, err)
+		}
+		return nil
+	case "copy":
+		value, err := pointerGet(root, op.From)
+		if err != nil {
+			return fromError(err)
+		}
+		return pointerAdd(root, op.Path, copyNode(value))
+	case "test":
+		value, err := pointerGet(root, op.Path)
+		if err != nil {
+			return err
+		}
+		if !nodesEqual(value, &op.Value) {
+			return fmt.Errorf("test failed, value does not match")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported operation %q", op.Op)
+}
+
+// fromError marks a missing from location of a move or copy, so it is reported instead of the path
+func fromError(err error) error {
+	if errors.Is(err, errTargetNotFound) {
+		return fmt.Errorf("%w: %w", errFromNotFound, err)
+	}
+	return err
+}
+
+// pointerGet returns the node referenced by a JSON pointer
+func pointerGet(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	node := root
+	for _, t := range tokens {
+		node, err = childNode(node, t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+// pointerAdd adds a value at the location of a JSON pointer, replacing existing object members
+func pointerAdd(root *yaml.Node, pointer string, value *yaml.Node) error {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		*root = *value
+		return nil
+	}
+
+	parent, err := pointerGet(root, jsonPointer(tokens[:len(tokens)-1]...))
+	if err != nil {
+		return err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		mappingSet(parent, last, value)
+	case yaml.SequenceNode:
+		if last == "-" {
+			parent.Content = append(parent.Content, value)
+			return nil
+		}
+		i, err := strconv.Atoi(last)
+		if err != nil || i < 0 || i > len(parent.Content) {
+			return fmt.Errorf("invalid array index %q", last)
+		}
+		parent.Content = append(parent.Content[:i], append([]*yaml.Node{value}, parent.Content[i:]...)...)
+	default:
+		return fmt.Errorf("parent of %s is not a container", pointer)
+	}
+
+	return nil
+}
+
+// pointerRemove removes and returns the value at the location of a JSON pointer
+func pointerRemove(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("can not remove the document root")
+	}
+
+	parent, err := pointerGet(root, jsonPointer(tokens[:len(tokens)-1]...))
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	value, err := childNode(parent, last)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent.Kind == yaml.MappingNode {
+		mappingDelete(parent, last)
+	} else {
+		i, _ := strconv.Atoi(last)
+		parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+	}
+
+	return value, nil
+}
+
+// childNode returns the member or array element of node referenced by a single pointer token
+func childNode(node *yaml.Node, token string) (*yaml.Node, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if value := mappingValue(node, token); value != nil {
+			return value, nil
+		}
+		return nil, fmt.Errorf("%w: member %q", errTargetNotFound, token)
+	case yaml.SequenceNode:
+		i, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array index %q", token)
+		}
+		if i < 0 || i >= len(node.Content) {
+			return nil, fmt.Errorf("%w: array index %q out of range", errTargetNotFound, token)
+		}
+		return node.Content[i], nil
+	}
+
+	return nil, fmt.Errorf("%w: %q can not be resolved on a scalar value", errTargetNotFound, token)
+}
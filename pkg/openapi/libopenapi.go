@@ -4,33 +4,39 @@ import (
 	"github.com/pb33f/libopenapi"
 	"github.com/pb33f/libopenapi/datamodel"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
-	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/primelib/primecodegen-app/pkg/config"
-	"github.com/rs/zerolog/log"
 )
 
-func OpenDocument(input []byte) (libopenapi.Document, error) {
+// OpenDocument parses the specification, remote references are only resolved if they are allowed by the remote policy, a nil policy disables them
+func OpenDocument(input []byte, remote *RemoteRefs) (libopenapi.Document, error) {
+	if err := CheckRemoteRefs(input, remote); err != nil {
+		return nil, err
+	}
+
 	// config
 	conf := datamodel.DocumentConfiguration{
 		AllowFileReferences:   true,
-		AllowRemoteReferences: true,
+		AllowRemoteReferences: remote.Enabled(),
+	}
+	if remote.Enabled() {
+		conf.RemoteURLHandler = remote.Get
 	}
 
 	// create a new document from specification bytes
 	document, err := libopenapi.NewDocumentWithConfiguration(input, &conf)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create document from spec")
+		return nil, newParseError(err)
 	}
 
 	return document, nil
 }
 
-func PatchDocument(document libopenapi.Document, specType string, specFormat string, specVersion float32, customizations config.Customization) libopenapi.Document {
+func PatchDocument(document libopenapi.Document, specType string, specFormat string, specVersion float32, customizations config.Customization) (libopenapi.Document, error) {
 	// detect type
 	if specType == "openapi" && specFormat == "oas3" {
 		v3doc, errors := document.BuildV3Model()
 		if len(errors) > 0 {
-			log.Fatal().Errs("errors", errors).Msg("cannot create v3 model from document")
+			return nil, newModelError(errors)
 		}
 		model := &v3doc.Model
 
@@ -65,16 +71,22 @@ func PatchDocument(document libopenapi.Document, specType string, specFormat str
 
 		// servers
 		if len(customizations.Servers) > 0 {
-			model.Servers = []*v3.Server{}
-			for _, server := range customizations.Servers {
-				model.Servers = append(model.Servers, &v3.Server{
-					URL:         server.URL,
-					Description: server.Description,
-					Variables:   nil,
-				})
-			}
+			model.Servers = customizeServers(model.Servers, customizations.Servers)
+		}
+
+		// security
+		if len(customizations.SecuritySchemes) > 0 {
+			customizeSecuritySchemes(model, customizations.SecuritySchemes)
+		}
+		if len(customizations.Security) > 0 {
+			model.Security = securityRequirements(customizations.Security)
+		}
+
+		// prune operations, tags and schemas
+		if HasPruning(customizations) {
+			pruneModel(model, customizations)
 		}
 	}
 
-	return document
+	return document, nil
 }
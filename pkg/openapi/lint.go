@@ -0,0 +1,187 @@
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// LintFinding is a rule violation in the specification
+type LintFinding struct {
+	Rule     string              `json:"rule"`
+	Severity config.LintSeverity `json:"severity"`
+	Pointer  string              `json:"pointer"`
+	Message  string              `json:"message"`
+}
+
+// LintError is returned if at least one finding has the error severity
+type LintError struct {
+	Findings []LintFinding
+}
+
+func (e *LintError) Error() string {
+	messages := make([]string, 0, len(e.Findings))
+	for _, f := range e.Findings {
+		messages = append(messages, fmt.Sprintf("%s: %s", f.Rule, f.Message))
+	}
+
+	return fmt.Sprintf("%d lint errors: %s", len(e.Findings), strings.Join(messages, ", "))
+}
+
+// lintOperation is an operation of the specification
+type lintOperation struct {
+	path    string
+	method  string
+	node    *yaml.Node
+	pointer string
+}
+
+// lintViolation is a single rule violation
+type lintViolation struct {
+	pointer string
+	message string
+}
+
+// lintRule checks the specification and returns every violation
+type lintRule struct {
+	severity config.LintSeverity
+	check    func(root *yaml.Node, operations []lintOperation) []lintViolation
+}
+
+// lintRules contains all rules with their default severity
+var lintRules = map[string]lintRule{
+	"operation-operationId":        {severity: config.LintSeverityWarn, check: lintOperationId},
+	"operation-operationId-unique": {severity: config.LintSeverityError, check: lintOperationIdUnique},
+	"operation-error-response":     {severity: config.LintSeverityWarn, check: lintErrorResponse},
+	"no-inline-schemas":            {severity: config.LintSeverityWarn, check: lintInlineSchemas},
+}
+
+// LintRuleNames returns the names of all lint rules
+func LintRuleNames() []string {
+	return slices.Sorted(maps.Keys(lintRules))
+}
+
+// Lint checks the specification against all rules, severities default to the rule defaults and can be overridden per rule
+func Lint(spec []byte, severities map[string]config.LintSeverity) ([]LintFinding, error) {
+	for name, severity := range severities {
+		if _, ok := lintRules[name]; !ok {
+			return nil, fmt.Errorf("unknown lint rule %s, available rules: %s", name, strings.Join(LintRuleNames(), ", "))
+		}
+		if severity != config.LintSeverityError && severity != config.LintSeverityWarn && severity != config.LintSeverityOff {
+			return nil, fmt.Errorf("invalid severity %s for lint rule %s", severity, name)
+		}
+	}
+
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []lintOperation
+	paths := mappingValue(root, "paths")
+	for _, p := range mappingKeys(paths) {
+		item := mappingValue(paths, p)
+		for _, method := range httpMethods {
+			if op := mappingValue(item, method); op != nil {
+				operations = append(operations, lintOperation{path: p, method: method, node: op, pointer: jsonPointer("paths", p, method)})
+			}
+		}
+	}
+
+	var findings []LintFinding
+	for _, name := range LintRuleNames() {
+		rule := lintRules[name]
+		severity := rule.severity
+		if s, ok := severities[name]; ok {
+			severity = s
+		}
+		if severity == config.LintSeverityOff {
+			continue
+		}
+
+		for _, v := range rule.check(root, operations) {
+			findings = append(findings, LintFinding{Rule: name, Severity: severity, Pointer: v.pointer, Message: v.message})
+		}
+	}
+
+	return findings, nil
+}
+
+// LintErrors returns the findings with the error severity
+func LintErrors(findings []LintFinding) []LintFinding {
+	var result []LintFinding
+	for _, f := range findings {
+		if f.Severity == config.LintSeverityError {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
+
+// lintOperationId reports operations without operationId
+func lintOperationId(_ *yaml.Node, operations []lintOperation) []lintViolation {
+	var result []lintViolation
+	for _, op := range operations {
+		if scalarValue(mappingValue(op.node, "operationId")) == "" {
+			result = append(result, lintViolation{op.pointer, fmt.Sprintf("operation %s %s has no operationId", strings.ToUpper(op.method), op.path)})
+		}
+	}
+
+	return result
+}
+
+// lintOperationIdUnique reports operationIds that are used by multiple operations
+func lintOperationIdUnique(_ *yaml.Node, operations []lintOperation) []lintViolation {
+	var result []lintViolation
+	seen := map[string]lintOperation{}
+	for _, op := range operations {
+		id := scalarValue(mappingValue(op.node, "operationId"))
+		if id == "" {
+			continue
+		}
+		if first, ok := seen[id]; ok {
+			result = append(result, lintViolation{op.pointer + "/operationId", fmt.Sprintf("operationId %s is already used by %s %s", id, strings.ToUpper(first.method), first.path)})
+			continue
+		}
+		seen[id] = op
+	}
+
+	return result
+}
+
+// lintErrorResponse reports operations that document neither a 4xx, 5xx nor a default response
+func lintErrorResponse(_ *yaml.Node, operations []lintOperation) []lintViolation {
+	var result []lintViolation
+	for _, op := range operations {
+		codes := mappingKeys(mappingValue(op.node, "responses"))
+		if !slices.ContainsFunc(codes, func(code string) bool {
+			return code == "default" || strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5")
+		}) {
+			result = append(result, lintViolation{op.pointer + "/responses", fmt.Sprintf("operation %s %s has no error response", strings.ToUpper(op.method), op.path)})
+		}
+	}
+
+	return result
+}
+
+// lintInlineSchemas reports object schemas that are defined inline in operations instead of referencing a named schema, nested inline schemas are reported once
+func lintInlineSchemas(_ *yaml.Node, operations []lintOperation) []lintViolation {
+	var result []lintViolation
+	for _, op := range operations {
+		var reported []string
+		for _, s := range schemaNodes(op.node) {
+			if mappingValue(s.node, "properties") == nil || slices.ContainsFunc(reported, func(p string) bool { return strings.HasPrefix(s.pointer, p+"/") }) {
+				continue
+			}
+			reported = append(reported, s.pointer)
+			result = append(result, lintViolation{op.pointer + s.pointer, "inline object schema, use a named schema instead"})
+		}
+	}
+
+	return result
+}
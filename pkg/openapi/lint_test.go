@@ -0,0 +1,71 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const lintSpec = `openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  owner:
+                    type: object
+                    properties:
+                      name:
+                        type: string
+        default:
+          description: error
+    post:
+      operationId: listPets
+      responses:
+        "201":
+          description: created
+  /owners:
+    get:
+      responses:
+        "404":
+          description: not found
+`
+
+func TestLint(t *testing.T) {
+	findings, err := Lint([]byte(lintSpec), map[string]config.LintSeverity{"operation-error-response": config.LintSeverityError})
+	assert.NoError(t, err)
+	assert.Equal(t, []LintFinding{
+		{Rule: "no-inline-schemas", Severity: config.LintSeverityWarn, Pointer: "/paths/~1pets/get/responses/200/content/application~1json/schema", Message: "inline object schema, use a named schema instead"},
+		{Rule: "operation-error-response", Severity: config.LintSeverityError, Pointer: "/paths/~1pets/post/responses", Message: "operation POST /pets has no error response"},
+		{Rule: "operation-operationId", Severity: config.LintSeverityWarn, Pointer: "/paths/~1owners/get", Message: "operation GET /owners has no operationId"},
+		{Rule: "operation-operationId-unique", Severity: config.LintSeverityError, Pointer: "/paths/~1pets/post/operationId", Message: "operationId listPets is already used by GET /pets"},
+	}, findings)
+	assert.Len(t, LintErrors(findings), 2)
+}
+
+func TestLintRuleOff(t *testing.T) {
+	findings, err := Lint([]byte(lintSpec), map[string]config.LintSeverity{
+		"no-inline-schemas":            config.LintSeverityOff,
+		"operation-error-response":     config.LintSeverityOff,
+		"operation-operationId":        config.LintSeverityOff,
+		"operation-operationId-unique": config.LintSeverityOff,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestLintUnknownRule(t *testing.T) {
+	_, err := Lint([]byte(lintSpec), map[string]config.LintSeverity{"does-not-exist": config.LintSeverityWarn})
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// MergeConflict describes a location that is defined with different content by multiple specifications
+type MergeConflict struct {
+	Pointer    string               `json:"pointer"`    // Pointer is the JSON pointer of the conflicting location
+	Source     int                  `json:"source"`     // Source is the index of the specification that caused the conflict
+	Resolution config.MergeStrategy `json:"resolution"` // Resolution is the strategy that was used to resolve the conflict
+}
+
+// MergeConflictError is returned if at least one conflict uses the error strategy
+type MergeConflictError struct {
+	Conflicts []MergeConflict
+}
+
+func (e *MergeConflictError) Error() string {
+	pointers := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		pointers = append(pointers, fmt.Sprintf("%s (source %d)", c.Pointer, c.Source))
+	}
+
+	return fmt.Sprintf("%d merge conflicts: %s", len(e.Conflicts), strings.Join(pointers, ", "))
+}
+
+// httpMethods are the path item keys that contain operations
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// swaggerComponents are the root keys of a swagger 2.0 specification that contain reusable objects
+var swaggerComponents = []string{"definitions", "parameters", "responses", "securityDefinitions"}
+
+// MergeSpecs merges multiple OpenAPI 3 or Swagger 2.0 specifications, the first specification is used as base for the result
+func MergeSpecs(specs []*yaml.Node, opts config.SpecMerge) (*yaml.Node, []MergeConflict, error) {
+	if len(specs) == 0 {
+		return nil, nil, fmt.Errorf("no specifications to merge")
+	}
+	for i, spec := range specs[1:] {
+		if isSwagger(spec) != isSwagger(specs[0]) {
+			return nil, nil, fmt.Errorf("source %d can not be merged, swagger 2.0 and openapi 3 specifications can not be mixed", i+1)
+		}
+	}
+
+	m := merger{opts: opts}
+	result := specs[0]
+	for i, spec := range specs[1:] {
+		m.source = i + 1
+		m.mergeRoot(result, spec)
+	}
+
+	var failed []MergeConflict
+	for _, c := range m.conflicts {
+		if c.Resolution == config.MergeStrategyError {
+			failed = append(failed, c)
+		}
+	}
+	if len(failed) > 0 {
+		return nil, m.conflicts, &MergeConflictError{Conflicts: failed}
+	}
+
+	return result, m.conflicts, nil
+}
+
+type merger struct {
+	opts      config.SpecMerge
+	source    int
+	conflicts []MergeConflict
+}
+
+func (m *merger) mergeRoot(target *yaml.Node, source *yaml.Node) {
+	for _, key := range mappingKeys(source) {
+		value := mappingValue(source, key)
+
+		switch key {
+		case "openapi", "swagger", "info", "host", "basePath":
+			// the first specification defines the document metadata
+		case "schemes", "consumes", "produces":
+			m.mergeUnique(target, key, value)
+		case "definitions", "parameters", "responses", "securityDefinitions":
+			m.mergeGroup(target, value, key)
+		case "servers":
+			m.mergeSequence(target, key, value, "url", m.opts.Servers)
+		case "tags":
+			m.mergeSequence(target, key, value, "name", m.opts.Tags)
+		case "security":
+			m.mergeUnique(target, key, value)
+		case "paths", "webhooks":
+			m.mergePaths(target, key, value)
+		case "components":
+			m.mergeComponents(target, value)
+		default:
+			if mappingValue(target, key) == nil {
+				mappingSet(target, key, value)
+			}
+		}
+	}
+}
+
+// mergeSequence merges a sequence of objects that are identified by the value of idKey
+func (m *merger) mergeSequence(target *yaml.Node, key string, source *yaml.Node, idKey string, strategy config.MergeStrategy) {
+	existing := mappingValue(target, key)
+	if existing == nil {
+		mappingSet(target, key, source)
+		return
+	}
+
+	for _, item := range source.Content {
+		id := mappingValue(item, idKey)
+		index := -1
+		for i, e := range existing.Content {
+			if eid := mappingValue(e, idKey); eid != nil && id != nil && eid.Value == id.Value {
+				index = i
+				break
+			}
+		}
+
+		if index < 0 {
+			existing.Content = append(existing.Content, item)
+		} else if !nodesEqual(existing.Content[index], item) && m.resolve(strategy, key, fmt.Sprint(index)) {
+			existing.Content[index] = item
+		}
+	}
+}
+
+// mergeUnique appends all items of a sequence that are not contained in the target sequence
+func (m *merger) mergeUnique(target *yaml.Node, key string, source *yaml.Node) {
+	existing := mappingValue(target, key)
+	if existing == nil {
+		mappingSet(target, key, source)
+		return
+	}
+
+	for _, item := range source.Content {
+		found := false
+		for _, e := range existing.Content {
+			if nodesEqual(e, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing.Content = append(existing.Content, item)
+		}
+	}
+}
+
+// mergePaths merges path items, operations of the same path are merged individually
+func (m *merger) mergePaths(target *yaml.Node, key string, source *yaml.Node) {
+	paths := mappingValue(target, key)
+	if paths == nil {
+		mappingSet(target, key, source)
+		return
+	}
+
+	for _, path := range mappingKeys(source) {
+		sourceItem := mappingValue(source, path)
+		targetItem := mappingValue(paths, path)
+		if targetItem == nil {
+			mappingSet(paths, path, sourceItem)
+			continue
+		}
+
+		for _, field := range mappingKeys(sourceItem) {
+			sourceValue := mappingValue(sourceItem, field)
+			targetValue := mappingValue(targetItem, field)
+			if targetValue == nil {
+				mappingSet(targetItem, field, sourceValue)
+			} else if !nodesEqual(targetValue, sourceValue) && m.resolve(m.opts.Paths, key, path, field) {
+				mappingSet(targetItem, field, sourceValue)
+			}
+		}
+	}
+}
+
+// mergeComponents merges all component types by name
+func (m *merger) mergeComponents(target *yaml.Node, source *yaml.Node) {
+	components := mappingValue(target, "components")
+	if components == nil {
+		mappingSet(target, "components", source)
+		return
+	}
+
+	for _, componentType := range mappingKeys(source) {
+		m.mergeGroup(components, mappingValue(source, componentType), "components", componentType)
+	}
+}
+
+// mergeGroup merges a group of named objects, tokens is the location of the group within the document
+func (m *merger) mergeGroup(parent *yaml.Node, source *yaml.Node, tokens ...string) {
+	key := tokens[len(tokens)-1]
+	targetGroup := mappingValue(parent, key)
+	if targetGroup == nil {
+		mappingSet(parent, key, source)
+		return
+	}
+	if targetGroup.Kind != yaml.MappingNode {
+		return // extensions
+	}
+
+	for _, name := range mappingKeys(source) {
+		sourceValue := mappingValue(source, name)
+		targetValue := mappingValue(targetGroup, name)
+		if targetValue == nil {
+			mappingSet(targetGroup, name, sourceValue)
+		} else if !nodesEqual(targetValue, sourceValue) && m.resolve(m.opts.Components, append(tokens, name)...) {
+			mappingSet(targetGroup, name, sourceValue)
+		}
+	}
+}
+
+// isSwagger reports whether the root node is a swagger 2.0 specification
+func isSwagger(root *yaml.Node) bool {
+	return mappingValue(root, "swagger") != nil
+}
+
+// resolve records a conflict and reports whether the value of the current source should replace the existing value
+func (m *merger) resolve(strategy config.MergeStrategy, tokens ...string) bool {
+	if strategy == "" {
+		strategy = config.MergeStrategyError
+	}
+	m.conflicts = append(m.conflicts, MergeConflict{
+		Pointer:    jsonPointer(tokens...),
+		Source:     m.source,
+		Resolution: strategy,
+	})
+
+	return strategy == config.MergeStrategyKeepLast
+}
@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const mergeSpecA = `openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+servers:
+  - url: https://a.example.com
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: object
+    Error:
+      type: object
+`
+
+const mergeSpecB = `openapi: 3.0.3
+info:
+  title: B
+  version: "2.0.0"
+servers:
+  - url: https://b.example.com
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      responses:
+        "201":
+          description: created
+  /owners:
+    get:
+      operationId: listOwners
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: string
+    Error:
+      type: object
+`
+
+func TestMergeAndPatch(t *testing.T) {
+	result, err := MergeAndPatch([][]byte{[]byte(mergeSpecA), []byte(mergeSpecB)}, PipelineOptions{
+		Patches: []Patch{{Name: "fix-missing-schema-title", Type: config.PatchTypeBuiltIn}},
+		Merge:   config.SpecMerge{Paths: config.MergeStrategyError, Components: config.MergeStrategyKeepFirst},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []MergeConflict{{Pointer: "/components/schemas/Pet", Source: 1, Resolution: config.MergeStrategyKeepFirst}}, result.Conflicts)
+
+	root, err := parseNode(result.Spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "A", mappingValue(mappingValue(root, "info"), "title").Value)
+	assert.Equal(t, []string{"/pets", "/owners"}, mappingKeys(mappingValue(root, "paths")))
+	assert.Equal(t, []string{"get", "post"}, mappingKeys(mappingValue(mappingValue(root, "paths"), "/pets")))
+	assert.Len(t, mappingValue(root, "servers").Content, 2)
+
+	pet := mappingValue(mappingValue(mappingValue(root, "components"), "schemas"), "Pet")
+	assert.Equal(t, "object", mappingValue(pet, "type").Value)
+	assert.Equal(t, "Pet", mappingValue(pet, "title").Value)
+}
+
+func TestMergeAndPatchConflictError(t *testing.T) {
+	_, err := MergeAndPatch([][]byte{[]byte(mergeSpecA), []byte(mergeSpecB)}, PipelineOptions{
+		Merge: config.SpecMerge{Paths: config.MergeStrategyError, Components: config.MergeStrategyError},
+	})
+
+	var conflictErr *MergeConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, "/components/schemas/Pet", conflictErr.Conflicts[0].Pointer)
+}
+
+func TestMergeAndPatchUnknownPatch(t *testing.T) {
+	_, err := MergeAndPatch([][]byte{[]byte(mergeSpecA)}, PipelineOptions{
+		Patches: []Patch{{Name: "does-not-exist", Type: config.PatchTypeBuiltIn}},
+	})
+
+	var patchErr *PatchError
+	assert.ErrorAs(t, err, &patchErr)
+	assert.Equal(t, "builtin:does-not-exist", patchErr.Patch)
+}
+
+func TestMergeAndPatchExternalPatch(t *testing.T) {
+	var called []string
+	result, err := MergeAndPatch([][]byte{[]byte(mergeSpecA)}, PipelineOptions{
+		Patches: []Patch{
+			{Name: "remove-examples", Type: config.PatchTypeBuiltIn},
+			{Name: "remove-examples", Type: config.PatchTypePrimeCodeGen},
+			{Name: "simplify-polymorphic-booleans", Type: config.PatchTypePrimeCodeGen},
+		},
+		External: func(name string, spec []byte) ([]byte, error) {
+			called = append(called, name)
+			return []byte(strings.Replace(string(spec), "title: A", "title: Patched", 1)), nil
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"remove-examples", "simplify-polymorphic-booleans"}, called)
+	assert.Contains(t, string(result.Spec), "title: Patched")
+	assert.Len(t, result.Patches, 3)
+}
+
+func TestMergeAndPatchExternalPatchUnsupported(t *testing.T) {
+	_, err := MergeAndPatch([][]byte{[]byte(mergeSpecA)}, PipelineOptions{
+		Patches: []Patch{{Name: "remove-examples", Type: config.PatchTypePrimeCodeGen}},
+	})
+
+	var patchErr *PatchError
+	assert.ErrorAs(t, err, &patchErr)
+	assert.Equal(t, "remove-examples", patchErr.Patch)
+}
+
+func TestMergeAndPatchParseError(t *testing.T) {
+	_, err := MergeAndPatch([][]byte{[]byte(mergeSpecA), []byte("openapi: 3.0.3\ninfo: [\n")}, PipelineOptions{})
+
+	var parseErr *ParseError
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 2, ErrorLocations(err)[0].Line)
+}
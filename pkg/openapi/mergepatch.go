@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyMergePatch applies a RFC 7386 JSON Merge Patch, deleting members that do not exist is reported as missed
+func applyMergePatch(root *yaml.Node, patch Patch) (PatchReport, error) {
+	report := PatchReport{Patch: patch.Name}
+
+	patchRoot, err := parseNode(patch.Content)
+	if err != nil {
+		return report, &PatchError{Patch: patch.Name, Err: fmt.Errorf("invalid merge patch: %w", err)}
+	}
+	mergePatchNode(root, patchRoot, nil, &report)
+
+	return report, nil
+}
+
+// mergePatchNode merges patch into target and returns the resulting node
+func mergePatchNode(target *yaml.Node, patch *yaml.Node, tokens []string, report *PatchReport) *yaml.Node {
+	if patch.Kind != yaml.MappingNode {
+		report.Touched = append(report.Touched, jsonPointer(tokens...))
+		return copyNode(patch)
+	}
+	if target == nil || target.Kind != yaml.MappingNode {
+		target = mappingNode()
+	}
+
+	for _, key := range mappingKeys(patch) {
+		value := mappingValue(patch, key)
+		path := append(slices.Clone(tokens), key)
+
+		if value.ShortTag() == "!!null" {
+			if mappingDelete(target, key) {
+				report.Touched = append(report.Touched, jsonPointer(path...))
+			} else {
+				report.Missed = append(report.Missed, jsonPointer(path...))
+			}
+			continue
+		}
+
+		mappingSet(target, key, mergePatchNode(mappingValue(target, key), value, path, report))
+	}
+
+	return target
+}
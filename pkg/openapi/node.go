@@ -0,0 +1,200 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseNode parses a yaml or json specification into the root mapping node
+func parseNode(input []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(input, &doc); err != nil {
+		return nil, newParseError(err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, newParseError(fmt.Errorf("specification root must be an object"))
+	}
+
+	return doc.Content[0], nil
+}
+
+// renderNode renders a node as yaml
+func renderNode(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, &RenderError{Err: err}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, &RenderError{Err: err}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value node of key in a mapping node, or nil if the key is not present
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// mappingSet sets the value of key in a mapping node, appending the key if it is not present
+func mappingSet(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+
+	node.Content = append(node.Content, stringNode(key), value)
+}
+
+// mappingDelete removes key from a mapping node and reports whether it was present
+func mappingDelete(node *yaml.Node, key string) bool {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// mappingKeys returns the keys of a mapping node in document order
+func mappingKeys(node *yaml.Node) []string {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := make([]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+
+	return keys
+}
+
+// stringNode creates a scalar string node
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// mappingNode creates an empty mapping node
+func mappingNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+// nodesEqual compares two nodes by content, ignoring style, comments and positions
+func nodesEqual(a *yaml.Node, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind == yaml.AliasNode {
+		return nodesEqual(a.Alias, b)
+	}
+	if b.Kind == yaml.AliasNode {
+		return nodesEqual(a, b.Alias)
+	}
+	if a.Kind != b.Kind || a.ShortTag() != b.ShortTag() || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !nodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// jsonPointer builds a RFC 6901 JSON pointer from the given reference tokens
+func jsonPointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(t))
+	}
+
+	return sb.String()
+}
+
+// parsePointer splits a RFC 6901 JSON pointer into its unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+
+	return tokens, nil
+}
+
+// copyNode creates a deep copy of a node
+func copyNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+
+	c := *node
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c.Content[i] = copyNode(child)
+	}
+
+	return &c
+}
+
+// nodeLocation is the position of a node within a document
+type nodeLocation struct {
+	Parent  *yaml.Node
+	Pointer string
+}
+
+// nodeLocations indexes the parent and JSON pointer of all nodes below root, mapping keys share the location of their value
+func nodeLocations(root *yaml.Node) map[*yaml.Node]nodeLocation {
+	locations := map[*yaml.Node]nodeLocation{}
+
+	var walk func(node *yaml.Node, pointer string)
+	walk = func(node *yaml.Node, pointer string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				p := pointer + jsonPointer(node.Content[i].Value)
+				locations[node.Content[i]] = nodeLocation{Parent: node, Pointer: p}
+				locations[node.Content[i+1]] = nodeLocation{Parent: node, Pointer: p}
+				walk(node.Content[i+1], p)
+			}
+		case yaml.SequenceNode:
+			for i, child := range node.Content {
+				p := pointer + jsonPointer(fmt.Sprint(i))
+				locations[child] = nodeLocation{Parent: node, Pointer: p}
+				walk(child, p)
+			}
+		}
+	}
+	walk(root, "")
+
+	return locations
+}
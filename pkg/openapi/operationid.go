@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/util"
+)
+
+// OperationIdRename is an operationId that was changed by the normalization
+type OperationIdRename struct {
+	Path   string `json:"path"`
+	Method string `json:"method"`
+	Old    string `json:"old"` // Old is the upstream operationId, empty if it was derived
+	New    string `json:"new"`
+}
+
+// HasOperationIdNormalization reports whether the customization changes operationIds
+func HasOperationIdNormalization(c config.CustomizationOperationIds) bool {
+	return len(c.StripPrefixes) > 0 || len(c.StripSuffixes) > 0 || c.Derive || c.CamelCase || c.Deduplicate || len(c.Rename) > 0
+}
+
+// NormalizeOperationIds renames the operationIds of all operations and returns every changed operationId
+func NormalizeOperationIds(spec []byte, opts config.CustomizationOperationIds) ([]byte, []OperationIdRename, error) {
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var renames []OperationIdRename
+	used := map[string]bool{}
+	paths := mappingValue(root, "paths")
+	for _, p := range mappingKeys(paths) {
+		item := mappingValue(paths, p)
+		for _, method := range httpMethods {
+			op := mappingValue(item, method)
+			if op == nil {
+				continue
+			}
+
+			old := scalarValue(mappingValue(op, "operationId"))
+			id, renamed := opts.Rename[old]
+			if !renamed || old == "" {
+				id = normalizeOperationId(old, method, p, opts)
+			}
+			if opts.Deduplicate && used[id] && id != "" {
+				base := id
+				for i := 2; used[id]; i++ {
+					id = fmt.Sprintf("%s%d", base, i)
+				}
+			}
+			used[id] = true
+
+			if id != old {
+				mappingSet(op, "operationId", stringNode(id))
+				renames = append(renames, OperationIdRename{Path: p, Method: method, Old: old, New: id})
+			}
+		}
+	}
+
+	output, err := renderNode(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output, renames, nil
+}
+
+// normalizeOperationId strips prefixes and suffixes, derives missing ids and converts them to camel case
+func normalizeOperationId(id string, method string, path string, opts config.CustomizationOperationIds) string {
+	for _, prefix := range opts.StripPrefixes {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	for _, suffix := range opts.StripSuffixes {
+		id = strings.TrimSuffix(id, suffix)
+	}
+	if id == "" && opts.Derive {
+		id = deriveOperationId(method, path)
+	}
+	if opts.CamelCase {
+		id = util.ToCamelCase(id)
+	}
+
+	return id
+}
+
+// deriveOperationId builds an operationId from method and path, e.g. GET /pets/{petId}/owners becomes getPetsByPetIdOwners
+func deriveOperationId(method string, path string) string {
+	words := []string{method}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			words = append(words, "by", strings.Trim(segment, "{}"))
+		} else if segment != "" {
+			words = append(words, segment)
+		}
+	}
+
+	return util.ToCamelCase(strings.Join(words, "_"))
+}
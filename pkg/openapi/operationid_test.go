@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const operationIdSpec = `openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+paths:
+  /v1/vulns/{id}:
+    get:
+      operationId: OSV_GetVulnById
+      responses:
+        "200":
+          description: ok
+  /v1/query:
+    post:
+      operationId: OSV_QueryAffected
+      responses:
+        "200":
+          description: ok
+  /v1/querybatch:
+    post:
+      operationId: OSV_QueryAffected
+      responses:
+        "200":
+          description: ok
+  /v1/pets/{petId}/owners:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+
+func TestNormalizeOperationIds(t *testing.T) {
+	output, renames, err := NormalizeOperationIds([]byte(operationIdSpec), config.CustomizationOperationIds{
+		StripPrefixes: []string{"OSV_"},
+		Derive:        true,
+		CamelCase:     true,
+		Deduplicate:   true,
+		Rename:        map[string]string{"OSV_GetVulnById": "getVulnerability"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []OperationIdRename{
+		{Path: "/v1/vulns/{id}", Method: "get", Old: "OSV_GetVulnById", New: "getVulnerability"},
+		{Path: "/v1/query", Method: "post", Old: "OSV_QueryAffected", New: "queryAffected"},
+		{Path: "/v1/querybatch", Method: "post", Old: "OSV_QueryAffected", New: "queryAffected2"},
+		{Path: "/v1/pets/{petId}/owners", Method: "get", Old: "", New: "getV1PetsByPetIdOwners"},
+	}, renames)
+
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	op, err := pointerGet(root, "/paths/~1v1~1query/post/operationId")
+	assert.NoError(t, err)
+	assert.Equal(t, "queryAffected", op.Value)
+}
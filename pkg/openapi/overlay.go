@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"fmt"
+
+	"github.com/speakeasy-api/jsonpath/pkg/jsonpath"
+	"github.com/speakeasy-api/jsonpath/pkg/jsonpath/config"
+	"github.com/speakeasy-api/jsonpath/pkg/overlay"
+	"gopkg.in/yaml.v3"
+)
+
+// applyOverlay applies the actions of an OpenAPI Overlay 1.0 document, targets that select no nodes are reported as missed
+func applyOverlay(root *yaml.Node, patch Patch) (PatchReport, error) {
+	report := PatchReport{Patch: patch.Name}
+
+	var o overlay.Overlay
+	if err := yaml.Unmarshal(patch.Content, &o); err != nil {
+		return report, &PatchError{Patch: patch.Name, Err: fmt.Errorf("invalid overlay: %w", err)}
+	}
+	if err := o.Validate(); err != nil {
+		return report, &PatchError{Patch: patch.Name, Err: fmt.Errorf("invalid overlay: %w", err)}
+	}
+
+	for _, action := range o.Actions {
+		path, err := jsonpath.NewPath(action.Target, config.WithPropertyNameExtension())
+		if err != nil {
+			return report, &PatchError{Patch: patch.Name, Pointer: action.Target, Err: fmt.Errorf("invalid target: %w", err)}
+		}
+
+		nodes := path.Query(root)
+		if len(nodes) == 0 {
+			report.Missed = append(report.Missed, action.Target)
+			continue
+		}
+
+		locations := nodeLocations(root)
+		for _, node := range nodes {
+			location := locations[node]
+			if action.Remove {
+				removeChild(location.Parent, node)
+			} else if !action.Update.IsZero() {
+				mergeOverlayNode(node, copyNode(&action.Update))
+			}
+			report.Touched = append(report.Touched, location.Pointer)
+		}
+	}
+
+	return report, nil
+}
+
+// removeChild removes node from its parent, selecting a mapping key or value removes the whole member
+func removeChild(parent *yaml.Node, node *yaml.Node) {
+	if parent == nil {
+		return
+	}
+
+	for i, child := range parent.Content {
+		if child != node {
+			continue
+		}
+
+		if parent.Kind == yaml.SequenceNode {
+			parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+		} else if i%2 == 1 {
+			parent.Content = append(parent.Content[:i-1], parent.Content[i+1:]...)
+		} else {
+			parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+		}
+		return
+	}
+}
+
+// mergeOverlayNode recursively merges objects, appends to arrays and replaces all other values
+func mergeOverlayNode(node *yaml.Node, update *yaml.Node) {
+	if node.Kind != update.Kind {
+		*node = *update
+		return
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for _, key := range mappingKeys(update) {
+			value := mappingValue(update, key)
+			if existing := mappingValue(node, key); existing != nil {
+				mergeOverlayNode(existing, value)
+			} else {
+				mappingSet(node, key, value)
+			}
+		}
+	case yaml.SequenceNode:
+		node.Content = append(node.Content, update.Content...)
+	default:
+		node.Value = update.Value
+		node.Tag = update.Tag
+	}
+}
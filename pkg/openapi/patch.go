@@ -0,0 +1,313 @@
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Patch is a named patch or the content of a patch file
+type Patch struct {
+	Name    string           // Name is the name of the built-in or primecodegen patch, or the path of the patch file
+	Type    config.PatchType // Type is the type of the patch
+	Content []byte           // Content is the content of the patch file
+}
+
+// PatchReport describes the locations a patch touched and the targets that matched nothing
+type PatchReport struct {
+	Patch   string   `json:"patch"`
+	Source  int      `json:"source"`            // Source is the index of the patched source, or -1 for the merged specification
+	Touched []string `json:"touched,omitempty"` // Touched contains the JSON pointers of all modified locations
+	Missed  []string `json:"missed,omitempty"`  // Missed contains all targets that did not match anything
+}
+
+// PatchError is returned if a patch can not be applied to a specification
+type PatchError struct {
+	Patch   string // Patch is the name of the patch
+	Pointer string // Pointer is the JSON pointer of the location that caused the error, if known
+	Err     error
+}
+
+func (e *PatchError) Error() string {
+	if e.Pointer != "" {
+		return fmt.Sprintf("patch %s failed at %s: %s", e.Patch, e.Pointer, e.Err)
+	}
+	return fmt.Sprintf("patch %s failed: %s", e.Patch, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// Step describes the patch and the source it was applied to
+func (r PatchReport) Step() string {
+	if r.Source >= 0 {
+		return fmt.Sprintf("input patch %s (source %d)", r.Patch, r.Source)
+	}
+	return "patch " + r.Patch
+}
+
+// namedPatch modifies the root node of a specification in place
+type namedPatch func(root *yaml.Node) error
+
+// ExternalPatch applies a primecodegen patch, it receives and returns the rendered specification
+type ExternalPatch func(name string, spec []byte) ([]byte, error)
+
+// namedPatches contains all built-in patches that can be referenced by name
+var namedPatches = map[string]namedPatch{
+	"fix-missing-schema-title": fixMissingSchemaTitle,
+	"fix-operation-tags":       fixOperationTags,
+	"remove-examples":          removeExamples,
+	"remove-extensions":        removeExtensions,
+}
+
+// PatchNames returns the names of all built-in patches
+func PatchNames() []string {
+	names := make([]string, 0, len(namedPatches))
+	for name := range namedPatches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LoadPatches resolves the patch references of the configuration, patch files are read relative to dir
+func LoadPatches(dir string, patches []config.SpecPatch) ([]Patch, error) {
+	var result []Patch
+	for _, p := range patches {
+		if p.File == "" {
+			patchType := p.Type
+			if patchType == "" {
+				patchType = config.PatchTypePrimeCodeGen
+			}
+			result = append(result, Patch{Name: p.Name, Type: patchType})
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, p.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read patch file: %w", err)
+		}
+		patchType := p.Type
+		if patchType == "" {
+			patchType = detectPatchType(content)
+		}
+		result = append(result, Patch{Name: p.File, Type: patchType, Content: content})
+	}
+
+	return result, nil
+}
+
+// detectPatchType detects the type of a patch file, arrays are json patches and objects with an overlay version are overlays
+func detectPatchType(content []byte) config.PatchType {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err == nil && len(doc.Content) > 0 {
+		if doc.Content[0].Kind == yaml.SequenceNode {
+			return config.PatchTypeJSONPatch
+		}
+		if mappingValue(doc.Content[0], "overlay") != nil {
+			return config.PatchTypeOverlay
+		}
+	}
+
+	return config.PatchTypeMergePatch
+}
+
+// ApplyPatches applies the patches in order, targets that match nothing are reported and do not cause an error
+func ApplyPatches(root *yaml.Node, patches []Patch) ([]PatchReport, error) {
+	return applyPatches(root, patches, nil)
+}
+
+// applyPatches applies the patches in order, primecodegen patches are passed to external
+func applyPatches(root *yaml.Node, patches []Patch, external ExternalPatch) ([]PatchReport, error) {
+	var reports []PatchReport
+	for _, p := range patches {
+		var report PatchReport
+		var err error
+
+		switch p.Type {
+		case config.PatchTypePrimeCodeGen, "":
+			report, err = applyExternalPatch(root, p.Name, external)
+		case config.PatchTypeBuiltIn:
+			report, err = applyNamedPatch(root, p.Name)
+		case config.PatchTypeJSONPatch:
+			report, err = applyJSONPatch(root, p)
+		case config.PatchTypeMergePatch:
+			report, err = applyMergePatch(root, p)
+		case config.PatchTypeOverlay:
+			report, err = applyOverlay(root, p)
+		default:
+			err = &PatchError{Patch: p.Name, Err: fmt.Errorf("unsupported patch type %q", p.Type)}
+		}
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// applyNamedPatch applies a built-in patch
+func applyNamedPatch(root *yaml.Node, name string) (PatchReport, error) {
+	patch, ok := namedPatches[name]
+	if !ok {
+		return PatchReport{}, &PatchError{Patch: config.BuiltInPatchPrefix + name, Err: fmt.Errorf("unknown built-in patch, available patches: %s", strings.Join(PatchNames(), ", "))}
+	}
+
+	if err := patch(root); err != nil {
+		return PatchReport{}, &PatchError{Patch: config.BuiltInPatchPrefix + name, Err: err}
+	}
+
+	return PatchReport{Patch: config.BuiltInPatchPrefix + name}, nil
+}
+
+// applyExternalPatch applies a primecodegen patch with external
+func applyExternalPatch(root *yaml.Node, name string, external ExternalPatch) (PatchReport, error) {
+	if external == nil {
+		return PatchReport{}, &PatchError{Patch: name, Err: fmt.Errorf("primecodegen patches are not supported, use %s<name> for built-in patches", config.BuiltInPatchPrefix)}
+	}
+
+	if err := externalPatch(name, external)(root); err != nil {
+		return PatchReport{}, &PatchError{Patch: name, Err: err}
+	}
+
+	return PatchReport{Patch: name}, nil
+}
+
+// externalPatch renders the specification, passes it to the external patch and replaces the root with the result
+func externalPatch(name string, external ExternalPatch) namedPatch {
+	return func(root *yaml.Node) error {
+		input, err := renderNode(root)
+		if err != nil {
+			return err
+		}
+		output, err := external(name, input)
+		if err != nil {
+			return err
+		}
+		patched, err := parseNode(output)
+		if err != nil {
+			return err
+		}
+		*root = *patched
+
+		return nil
+	}
+}
+
+// fixMissingSchemaTitle sets the title of all component schemas without a title to the component name
+func fixMissingSchemaTitle(root *yaml.Node) error {
+	schemas := mappingValue(mappingValue(root, "components"), "schemas")
+	for _, name := range mappingKeys(schemas) {
+		schema := mappingValue(schemas, name)
+		if schema.Kind != yaml.MappingNode || mappingValue(schema, "$ref") != nil || mappingValue(schema, "title") != nil {
+			continue
+		}
+		mappingSet(schema, "title", stringNode(name))
+	}
+
+	return nil
+}
+
+// fixOperationTags assigns the default tag to untagged operations and declares all used tags at the document level
+func fixOperationTags(root *yaml.Node) error {
+	var used []string
+	paths := mappingValue(root, "paths")
+	for _, path := range mappingKeys(paths) {
+		item := mappingValue(paths, path)
+		for _, method := range httpMethods {
+			op := mappingValue(item, method)
+			if op == nil {
+				continue
+			}
+
+			tags := mappingValue(op, "tags")
+			if tags == nil || len(tags.Content) == 0 {
+				tags = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{stringNode("default")}}
+				mappingSet(op, "tags", tags)
+			}
+			for _, t := range tags.Content {
+				if !slices.Contains(used, t.Value) {
+					used = append(used, t.Value)
+				}
+			}
+		}
+	}
+
+	declared := mappingValue(root, "tags")
+	if declared == nil {
+		declared = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		mappingSet(root, "tags", declared)
+	}
+	for _, name := range used {
+		found := false
+		for _, t := range declared.Content {
+			if v := mappingValue(t, "name"); v != nil && v.Value == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tag := mappingNode()
+			mappingSet(tag, "name", stringNode(name))
+			declared.Content = append(declared.Content, tag)
+		}
+	}
+
+	return nil
+}
+
+// removeExamples removes all example and examples properties
+func removeExamples(root *yaml.Node) error {
+	walkMappings(root, "", func(node *yaml.Node, parentKey string) {
+		if parentKey == "properties" {
+			return
+		}
+		mappingDelete(node, "example")
+		mappingDelete(node, "examples")
+	})
+
+	return nil
+}
+
+// removeExtensions removes all specification extensions (x-*)
+func removeExtensions(root *yaml.Node) error {
+	walkMappings(root, "", func(node *yaml.Node, parentKey string) {
+		if parentKey == "properties" {
+			return
+		}
+		for _, key := range mappingKeys(node) {
+			if strings.HasPrefix(key, "x-") {
+				mappingDelete(node, key)
+			}
+		}
+	})
+
+	return nil
+}
+
+// walkMappings calls fn for every mapping node below and including node, together with the key it is stored under
+func walkMappings(node *yaml.Node, key string, fn func(node *yaml.Node, parentKey string)) {
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		fn(node, key)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkMappings(node.Content[i+1], node.Content[i].Value, fn)
+		}
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			walkMappings(child, key, fn)
+		}
+	}
+}
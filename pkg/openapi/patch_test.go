@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestApplyPatches(t *testing.T) {
+	root, err := parseNode([]byte(mergeSpecA))
+	assert.NoError(t, err)
+
+	jsonPatch := `[
+		{"op": "replace", "path": "/info/title", "value": "Patched"},
+		{"op": "add", "path": "/servers/-", "value": {"url": "https://c.example.com"}},
+		{"op": "remove", "path": "/paths/~1pets/get/operationId"}
+	]`
+	mergePatch := `
+components:
+  schemas:
+    Error: null
+    Missing: null
+`
+	overlay := `
+overlay: 1.0.0
+info:
+  title: test
+  version: 1.0.0
+actions:
+  - target: $.paths['/pets'].get
+    update:
+      summary: List all pets
+  - target: $.paths['/owners']
+    remove: true
+`
+	patches := []Patch{
+		{Name: "patch.json", Type: detectPatchType([]byte(jsonPatch)), Content: []byte(jsonPatch)},
+		{Name: "merge.yaml", Type: detectPatchType([]byte(mergePatch)), Content: []byte(mergePatch)},
+		{Name: "overlay.yaml", Type: detectPatchType([]byte(overlay)), Content: []byte(overlay)},
+	}
+	assert.Equal(t, config.PatchTypeJSONPatch, patches[0].Type)
+	assert.Equal(t, config.PatchTypeMergePatch, patches[1].Type)
+	assert.Equal(t, config.PatchTypeOverlay, patches[2].Type)
+
+	reports, err := ApplyPatches(root, patches)
+	assert.NoError(t, err)
+	assert.Equal(t, []PatchReport{
+		{Patch: "patch.json", Touched: []string{"/info/title", "/servers/-", "/paths/~1pets/get/operationId"}},
+		{Patch: "merge.yaml", Touched: []string{"/components/schemas/Error"}, Missed: []string{"/components/schemas/Missing"}},
+		{Patch: "overlay.yaml", Touched: []string{"/paths/~1pets/get"}, Missed: []string{"$.paths['/owners']"}},
+	}, reports)
+
+	assert.Equal(t, "Patched", mappingValue(mappingValue(root, "info"), "title").Value)
+	assert.Len(t, mappingValue(root, "servers").Content, 2)
+	get := mappingValue(mappingValue(mappingValue(root, "paths"), "/pets"), "get")
+	assert.Nil(t, mappingValue(get, "operationId"))
+	assert.Equal(t, "List all pets", mappingValue(get, "summary").Value)
+	assert.Equal(t, []string{"Pet"}, mappingKeys(mappingValue(mappingValue(root, "components"), "schemas")))
+}
+
+func TestApplyPatchesJSONPatchMissed(t *testing.T) {
+	root, err := parseNode([]byte(mergeSpecA))
+	assert.NoError(t, err)
+	patches := []Patch{{Name: "patch.json", Type: config.PatchTypeJSONPatch, Content: []byte(`[
+		{"op": "remove", "path": "/paths/~1owners"},
+		{"op": "move", "from": "/info/x-missing", "path": "/info/x-moved"},
+		{"op": "copy", "from": "/info/x-missing", "path": "/info/x-copied"},
+		{"op": "add", "path": "/info/x-patched", "value": true}
+	]`)}}
+
+	reports, err := ApplyPatches(root, patches)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/paths/~1owners", "/info/x-missing", "/info/x-missing"}, reports[0].Missed)
+	assert.Equal(t, []string{"/info/x-patched"}, reports[0].Touched)
+}
+
+func TestApplyPatchesJSONPatchAtomic(t *testing.T) {
+	root, err := parseNode([]byte(mergeSpecA))
+	assert.NoError(t, err)
+	title := scalarValue(mappingValue(mappingValue(root, "info"), "title"))
+	patches := []Patch{{Name: "patch.json", Type: config.PatchTypeJSONPatch, Content: []byte(`[
+		{"op": "add", "path": "/info/x-patched", "value": true},
+		{"op": "test", "path": "/info/title", "value": "other"},
+		{"op": "replace", "path": "/info/title", "value": "CHANGED"}
+	]`)}}
+
+	reports, err := ApplyPatches(root, patches)
+	var patchErr *PatchError
+	if assert.ErrorAs(t, err, &patchErr) {
+		assert.Equal(t, "patch.json", patchErr.Patch)
+		assert.Equal(t, "/info/title", patchErr.Pointer)
+	}
+	assert.Empty(t, reports)
+	assert.Equal(t, title, scalarValue(mappingValue(mappingValue(root, "info"), "title")))
+	assert.Nil(t, mappingValue(mappingValue(root, "info"), "x-patched"), "operations before the failed test are not applied")
+
+	_, err = ApplyPatches(root, []Patch{{Name: "patch.json", Type: config.PatchTypeJSONPatch, Content: []byte(`[{"op": "rename", "path": "/info"}]`)}})
+	assert.ErrorContains(t, err, `unsupported operation "rename"`)
+
+	// a move to a missing target fails instead of dropping the removed value
+	_, err = ApplyPatches(root, []Patch{{Name: "patch.json", Type: config.PatchTypeJSONPatch, Content: []byte(`[{"op": "move", "from": "/info/title", "path": "/x-missing/title"}]`)}})
+	assert.ErrorAs(t, err, &patchErr)
+	assert.Equal(t, title, scalarValue(mappingValue(mappingValue(root, "info"), "title")))
+}
+
+func TestLoadPatches(t *testing.T) {
+	var refs []config.SpecPatch
+	err := yaml.Unmarshal([]byte(`
+- remove-examples
+- builtin:remove-examples
+- name: fix-operation-tags
+  type: builtin
+- name: builtin:remove-extensions
+- file: patches/servers.json
+  type: json-patch
+`), &refs)
+	assert.NoError(t, err)
+
+	patches, err := LoadPatches(t.TempDir(), refs[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, []Patch{
+		{Name: "remove-examples", Type: config.PatchTypePrimeCodeGen},
+		{Name: "remove-examples", Type: config.PatchTypeBuiltIn},
+		{Name: "fix-operation-tags", Type: config.PatchTypeBuiltIn},
+		{Name: "remove-extensions", Type: config.PatchTypeBuiltIn},
+	}, patches)
+	assert.Equal(t, config.SpecPatch{File: "patches/servers.json", Type: config.PatchTypeJSONPatch}, refs[4])
+}
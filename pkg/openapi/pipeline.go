@@ -0,0 +1,68 @@
+package openapi
+
+import (
+	"fmt"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineOptions configures the merge and patch pipeline
+type PipelineOptions struct {
+	InputPatches []Patch          // InputPatches are applied to every source before merging
+	Patches      []Patch          // Patches are applied to the merged specification
+	Merge        config.SpecMerge // Merge configures the conflict strategies
+	External     ExternalPatch    // External applies primecodegen patches, they fail if nil
+}
+
+// PipelineResult is the merged and patched specification
+type PipelineResult struct {
+	Spec      []byte
+	Conflicts []MergeConflict
+	Patches   []PatchReport
+}
+
+// MergeAndPatch applies the input patches to every source, merges the sources and applies the patches to the merged result
+func MergeAndPatch(sources [][]byte, opts PipelineOptions) (PipelineResult, error) {
+	var result PipelineResult
+
+	var specs []*yaml.Node
+	for i, source := range sources {
+		root, err := parseNode(source)
+		if err != nil {
+			return result, fmt.Errorf("failed to parse source %d: %w", i, err)
+		}
+
+		reports, err := applyPatches(root, opts.InputPatches, opts.External)
+		for _, r := range reports {
+			r.Source = i
+			result.Patches = append(result.Patches, r)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to patch source %d: %w", i, err)
+		}
+		specs = append(specs, root)
+	}
+
+	merged, conflicts, err := MergeSpecs(specs, opts.Merge)
+	result.Conflicts = conflicts
+	if err != nil {
+		return result, err
+	}
+
+	reports, err := applyPatches(merged, opts.Patches, opts.External)
+	for _, r := range reports {
+		r.Source = -1
+		result.Patches = append(result.Patches, r)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.Spec, err = renderNode(merged)
+	if err != nil {
+		return result, fmt.Errorf("failed to render merged specification: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,275 @@
+package openapi
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// prunableComponents are the component types that are removed if they are not referenced, security schemes are referenced by name and always kept
+var prunableComponents = []string{"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "links", "callbacks", "pathItems"}
+
+// HasPruning reports whether the customization removes parts of the specification
+func HasPruning(customizations config.Customization) bool {
+	return len(customizations.PruneOperations) > 0 || len(customizations.PruneTags) > 0 || len(customizations.PruneSchemas) > 0
+}
+
+// pruneModel removes operations by operationId or glob, operations with a pruned tag and schemas from the model.
+// Path items are only removed if their last operation was pruned, items without operations are kept.
+func pruneModel(model *v3.Document, customizations config.Customization) {
+	if model.Paths != nil && model.Paths.PathItems != nil {
+		var emptyPaths []string
+		for p, item := range model.Paths.PathItems.FromOldest() {
+			pruned := false
+			for method, op := range item.GetOperations().FromOldest() {
+				if matchesAny(customizations.PruneOperations, op.OperationId) || slices.ContainsFunc(op.Tags, func(tag string) bool { return matchesAny(customizations.PruneTags, tag) }) {
+					log.Debug().Str("path", p).Str("method", method).Str("operationId", op.OperationId).Msg("pruning operation")
+					removeOperation(item, method)
+					pruned = true
+				}
+			}
+			if pruned && orderedmap.Len(item.GetOperations()) == 0 {
+				emptyPaths = append(emptyPaths, p)
+			}
+		}
+		for _, p := range emptyPaths {
+			model.Paths.PathItems.Delete(p)
+		}
+	}
+
+	model.Tags = slices.DeleteFunc(model.Tags, func(tag *base.Tag) bool {
+		return matchesAny(customizations.PruneTags, tag.Name)
+	})
+
+	if model.Components != nil && model.Components.Schemas != nil {
+		for _, name := range slices.Collect(model.Components.Schemas.KeysFromOldest()) {
+			if matchesAny(customizations.PruneSchemas, name) {
+				log.Debug().Str("schema", name).Msg("pruning schema")
+				model.Components.Schemas.Delete(name)
+			}
+		}
+	}
+}
+
+// removeOperation removes the operation with the given method from a path item
+func removeOperation(item *v3.PathItem, method string) {
+	switch method {
+	case "get":
+		item.Get = nil
+	case "put":
+		item.Put = nil
+	case "post":
+		item.Post = nil
+	case "delete":
+		item.Delete = nil
+	case "options":
+		item.Options = nil
+	case "head":
+		item.Head = nil
+	case "patch":
+		item.Patch = nil
+	case "trace":
+		item.Trace = nil
+	}
+}
+
+// matchesAny reports whether name matches one of the patterns, patterns support path.Match globs
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if p == name {
+			return true
+		}
+		if ok, err := path.Match(p, name); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnreferencedComponents returns the JSON pointers of all components that are not reachable from outside the components object
+func UnreferencedComponents(spec []byte) ([]string, error) {
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse specification: %w", err)
+	}
+
+	var unreferenced []string
+	reachable := reachableComponents(root)
+	walkComponents(root, func(group *yaml.Node, name string, pointer string) {
+		if !reachable["#"+pointer] {
+			unreferenced = append(unreferenced, pointer)
+		}
+	})
+
+	return unreferenced, nil
+}
+
+// RemoveUnreferencedComponents removes all components that are not reachable from outside the components object and returns the JSON pointers of the removed components.
+// Components in keep are never removed, e.g. components that were already unreferenced before pruning.
+func RemoveUnreferencedComponents(spec []byte, keep []string) ([]byte, []string, error) {
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse specification: %w", err)
+	}
+
+	removed := removeUnreferencedComponents(root, keep)
+	output, err := renderNode(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render specification: %w", err)
+	}
+
+	return output, removed, nil
+}
+
+func removeUnreferencedComponents(root *yaml.Node, keep []string) []string {
+	reachable := reachableComponents(root)
+
+	var removed []string
+	walkComponents(root, func(group *yaml.Node, name string, pointer string) {
+		if !reachable["#"+pointer] && !slices.Contains(keep, pointer) {
+			mappingDelete(group, name)
+			removed = append(removed, pointer)
+		}
+	})
+
+	// drop empty groups
+	for _, tokens := range componentGroups(root) {
+		parent := root
+		for _, t := range tokens[:len(tokens)-1] {
+			parent = mappingValue(parent, t)
+		}
+		key := tokens[len(tokens)-1]
+		if group := mappingValue(parent, key); group != nil && len(group.Content) == 0 {
+			mappingDelete(parent, key)
+		}
+	}
+	if components := mappingValue(root, "components"); components != nil && len(components.Content) == 0 {
+		mappingDelete(root, "components")
+	}
+
+	return removed
+}
+
+// walkComponents calls fn for every component of the prunable component groups
+func walkComponents(root *yaml.Node, fn func(group *yaml.Node, name string, pointer string)) {
+	for _, tokens := range componentGroups(root) {
+		group := root
+		for _, t := range tokens {
+			group = mappingValue(group, t)
+		}
+		for _, name := range mappingKeys(group) {
+			fn(group, name, jsonPointer(append(tokens, name)...))
+		}
+	}
+}
+
+// reachableComponents returns the references of all components that are reachable from outside the prunable component groups
+func reachableComponents(root *yaml.Node) map[string]bool {
+	groups := componentGroups(root)
+	isGroup := func(pointer string) bool {
+		return slices.ContainsFunc(groups, func(g []string) bool { return jsonPointer(g...) == pointer })
+	}
+
+	// collect references from everything but the prunable component groups
+	var queue []string
+	var collect func(node *yaml.Node, pointer string)
+	collect = func(node *yaml.Node, pointer string) {
+		for _, key := range mappingKeys(node) {
+			p := pointer + jsonPointer(key)
+			switch {
+			case isGroup(p):
+			case slices.ContainsFunc(groups, func(g []string) bool { return strings.HasPrefix(jsonPointer(g...), p+"/") }):
+				collect(mappingValue(node, key), p)
+			default:
+				queue = append(queue, collectRefs(mappingValue(node, key))...)
+			}
+		}
+	}
+	collect(root, "")
+
+	// follow references transitively
+	reachable := map[string]bool{}
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		if !strings.HasPrefix(ref, "#/") {
+			continue
+		}
+		ref = componentRef(ref, groups)
+		if reachable[ref] {
+			continue
+		}
+		reachable[ref] = true
+
+		if node, err := pointerGet(root, strings.TrimPrefix(ref, "#")); err == nil {
+			queue = append(queue, collectRefs(node)...)
+		}
+	}
+
+	return reachable
+}
+
+// componentRef reduces a reference into a component to the reference of the component itself, e.g. #/components/schemas/Pet/properties/id to #/components/schemas/Pet
+func componentRef(ref string, groups [][]string) string {
+	tokens, err := parsePointer(strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return ref
+	}
+	for _, g := range groups {
+		if len(tokens) > len(g) && slices.Equal(tokens[:len(g)], g) {
+			return "#" + jsonPointer(tokens[:len(g)+1]...)
+		}
+	}
+
+	return ref
+}
+
+// componentGroups returns the locations of all component groups that can be removed if they are not referenced
+func componentGroups(root *yaml.Node) [][]string {
+	var groups [][]string
+	if isSwagger(root) {
+		for _, key := range swaggerComponents {
+			if key != "securityDefinitions" {
+				groups = append(groups, []string{key})
+			}
+		}
+		return groups
+	}
+
+	for _, componentType := range prunableComponents {
+		groups = append(groups, []string{"components", componentType})
+	}
+
+	return groups
+}
+
+// collectRefs returns all local references below node, including discriminator mappings
+func collectRefs(node *yaml.Node) []string {
+	var refs []string
+	walkMappings(node, "", func(n *yaml.Node, parentKey string) {
+		if ref := mappingValue(n, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode && parentKey != "properties" {
+			refs = append(refs, ref.Value)
+		}
+		if parentKey == "discriminator" {
+			mapping := mappingValue(n, "mapping")
+			for _, key := range mappingKeys(mapping) {
+				value := mappingValue(mapping, key).Value
+				if !strings.HasPrefix(value, "#") {
+					value = "#" + jsonPointer("components", "schemas", value)
+				}
+				refs = append(refs, value)
+			}
+		}
+	})
+
+	return refs
+}
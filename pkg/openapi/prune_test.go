@@ -0,0 +1,146 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const pruneSpec = `openapi: 3.0.3
+info:
+  title: Pets
+  version: "1.0.0"
+tags:
+  - name: pets
+  - name: internal
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/PetList'
+  /admin/pets:
+    delete:
+      operationId: adminDeletePets
+      tags: [pets]
+      responses:
+        "204":
+          $ref: '#/components/responses/NoContent'
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: string
+  /metrics:
+    get:
+      operationId: getMetrics
+      tags: [internal]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Metrics'
+components:
+  schemas:
+    PetList:
+      type: array
+      items:
+        $ref: '#/components/schemas/Pet'
+    Pet:
+      type: object
+    Metrics:
+      type: object
+    Debug:
+      type: object
+    Shared:
+      type: object
+  responses:
+    NoContent:
+      description: no content
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+`
+
+func TestPruneAndRemoveUnreferencedComponents(t *testing.T) {
+	doc, err := OpenDocument([]byte(pruneSpec), nil)
+	assert.NoError(t, err)
+	specInfo := doc.GetSpecInfo()
+	customization := config.Customization{
+		PruneOperations: []string{"admin*"},
+		PruneTags:       []string{"internal"},
+		PruneSchemas:    []string{"Debug"},
+	}
+	doc, err = PatchDocument(doc, specInfo.SpecType, specInfo.SpecFormat, specInfo.VersionNumeric, customization)
+	assert.NoError(t, err)
+	output, err := doc.Render()
+	assert.NoError(t, err)
+
+	// components that were unreferenced before pruning are kept
+	unreferenced, err := UnreferencedComponents([]byte(pruneSpec))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/components/schemas/Debug", "/components/schemas/Shared"}, unreferenced)
+	output, removed, err := RemoveUnreferencedComponents(output, unreferenced)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"/components/schemas/Metrics", "/components/responses/NoContent"}, removed)
+
+	// path items without operations are only removed if their operations were pruned
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/pets", "/pets/{id}"}, mappingKeys(mappingValue(root, "paths")))
+	assert.Len(t, mappingValue(root, "tags").Content, 1)
+	components := mappingValue(root, "components")
+	assert.Equal(t, []string{"PetList", "Pet", "Shared"}, mappingKeys(mappingValue(components, "schemas")))
+	assert.Nil(t, mappingValue(components, "responses"))
+	assert.NotNil(t, mappingValue(components, "securitySchemes"))
+}
+
+func TestRemoveUnreferencedComponentsNestedPointer(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: Pets
+  version: "1.0.0"
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPetId
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet/properties/id'
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id:
+          $ref: '#/components/schemas/PetId'
+    PetId:
+      type: string
+    Unused:
+      type: object
+`
+	output, removed, err := RemoveUnreferencedComponents([]byte(spec), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/components/schemas/Unused"}, removed)
+
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Pet", "PetId"}, mappingKeys(mappingValue(mappingValue(root, "components"), "schemas")))
+}
@@ -0,0 +1,195 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultRemoteRefMaxCount = 50
+	defaultRemoteRefMaxSize  = 10 << 20
+	maxRemoteRefRedirects    = 5
+)
+
+// RemoteRefError is returned if a remote reference is blocked by the remote reference policy
+type RemoteRefError struct {
+	Ref    string
+	Reason string
+}
+
+func (e *RemoteRefError) Error() string {
+	return fmt.Sprintf("remote reference %s is blocked: %s", e.Ref, e.Reason)
+}
+
+// RemoteRefs fetches remote references that are allowed by the policy, it enforces the count and size limits across all fetches
+type RemoteRefs struct {
+	policy config.SpecRemoteRefs
+	client *http.Client
+	mu     sync.Mutex
+	count  int
+}
+
+// NewRemoteRefs creates a fetcher for the policy, limits that are not configured use their defaults
+func NewRemoteRefs(policy config.SpecRemoteRefs) *RemoteRefs {
+	if len(policy.Schemes) == 0 {
+		policy.Schemes = []string{"https"}
+	}
+	if policy.MaxCount <= 0 {
+		policy.MaxCount = defaultRemoteRefMaxCount
+	}
+	if policy.MaxSize <= 0 {
+		policy.MaxSize = defaultRemoteRefMaxSize
+	}
+
+	r := &RemoteRefs{policy: policy}
+	r.client = &http.Client{
+		// redirects must not leave the allow-list
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRemoteRefRedirects {
+				return &RemoteRefError{Ref: via[0].URL.String(), Reason: fmt.Sprintf("more than %d redirects", maxRemoteRefRedirects)}
+			}
+			return r.Check(req.URL.String())
+		},
+	}
+
+	return r
+}
+
+// Enabled reports whether any host is allowed
+func (r *RemoteRefs) Enabled() bool {
+	return r != nil && len(r.policy.Hosts) > 0
+}
+
+// Check returns a RemoteRefError if the scheme or host of the reference is not allowed
+func (r *RemoteRefs) Check(ref string) error {
+	if !r.Enabled() {
+		return &RemoteRefError{Ref: ref, Reason: "remote references are disabled, allow the host in spec.remoteRefs.hosts"}
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return &RemoteRefError{Ref: ref, Reason: fmt.Sprintf("invalid url: %v", err)}
+	}
+	if !slices.Contains(r.policy.Schemes, strings.ToLower(u.Scheme)) {
+		return &RemoteRefError{Ref: ref, Reason: fmt.Sprintf("scheme %q is not allowed", u.Scheme)}
+	}
+	if !hostAllowed(r.policy.Hosts, u.Hostname()) {
+		return &RemoteRefError{Ref: ref, Reason: fmt.Sprintf("host %q is not allowed", u.Hostname())}
+	}
+
+	return nil
+}
+
+// Get requests an allowed remote reference, the body fails once it exceeds the size limit
+func (r *RemoteRefs) Get(ref string) (*http.Response, error) {
+	if err := r.Check(ref); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.count++
+	count := r.count
+	r.mu.Unlock()
+	if count > r.policy.MaxCount {
+		return nil, &RemoteRefError{Ref: ref, Reason: fmt.Sprintf("more than %d remote references", r.policy.MaxCount)}
+	}
+
+	response, err := r.client.Get(ref)
+	if err != nil {
+		var refErr *RemoteRefError
+		if errors.As(err, &refErr) {
+			return nil, refErr
+		}
+		return nil, fmt.Errorf("failed to fetch remote reference %s: %w", ref, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("failed to fetch remote reference %s: %s", ref, response.Status)
+	}
+	if response.ContentLength > r.policy.MaxSize {
+		_ = response.Body.Close()
+		return nil, &RemoteRefError{Ref: ref, Reason: fmt.Sprintf("size %d exceeds the limit of %d bytes", response.ContentLength, r.policy.MaxSize)}
+	}
+	response.Body = &limitedBody{ReadCloser: response.Body, ref: ref, remaining: r.policy.MaxSize}
+
+	return response, nil
+}
+
+// Fetch downloads an allowed remote reference
+func (r *RemoteRefs) Fetch(ref string) ([]byte, error) {
+	response, err := r.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return io.ReadAll(response.Body)
+}
+
+// CheckRemoteRefs returns an error naming every remote reference of the spec that is blocked by the policy, a nil policy blocks all remote references
+func CheckRemoteRefs(spec []byte, remote *RemoteRefs) error {
+	root, err := parseNode(spec)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	seen := map[string]bool{}
+	walkMappings(root, "", func(n *yaml.Node, parentKey string) {
+		ref := mappingValue(n, "$ref")
+		if ref == nil || ref.Kind != yaml.ScalarNode || parentKey == "properties" {
+			return
+		}
+		location, _, _ := strings.Cut(ref.Value, "#")
+		if !strings.Contains(location, "://") || seen[location] {
+			return
+		}
+		seen[location] = true
+		if err := remote.Check(location); err != nil {
+			errs = append(errs, err)
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// hostAllowed matches a hostname against the allow-list, *.example.com matches all subdomains of example.com
+func hostAllowed(hosts []string, hostname string) bool {
+	hostname = strings.ToLower(hostname)
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if suffix, ok := strings.CutPrefix(h, "*."); ok {
+			if strings.HasSuffix(hostname, "."+suffix) {
+				return true
+			}
+		} else if hostname == h {
+			return true
+		}
+	}
+
+	return false
+}
+
+// limitedBody fails reading once more than the remaining bytes are read
+type limitedBody struct {
+	io.ReadCloser
+	ref       string
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, &RemoteRefError{Ref: b.ref, Reason: "size exceeds the limit"}
+	}
+
+	return n, err
+}
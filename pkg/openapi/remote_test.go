@@ -0,0 +1,84 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteRefsFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "http://internal.example.com/secret", http.StatusFound)
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+		default:
+			_, _ = w.Write([]byte("type: object\n"))
+		}
+	}))
+	defer server.Close()
+
+	remote := NewRemoteRefs(config.SpecRemoteRefs{Hosts: []string{"127.0.0.1"}, Schemes: []string{"http"}, MaxCount: 3, MaxSize: 50})
+	content, err := remote.Fetch(server.URL + "/pet.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "type: object\n", string(content))
+
+	var refErr *RemoteRefError
+	_, err = remote.Fetch(server.URL + "/redirect")
+	require.ErrorAs(t, err, &refErr)
+	assert.Equal(t, "http://internal.example.com/secret", refErr.Ref)
+
+	_, err = remote.Fetch(server.URL + "/large")
+	require.ErrorAs(t, err, &refErr)
+	assert.Contains(t, refErr.Reason, "size")
+
+	_, err = remote.Fetch(server.URL + "/pet.yaml")
+	require.ErrorAs(t, err, &refErr)
+	assert.Equal(t, "more than 3 remote references", refErr.Reason)
+}
+
+func TestRemoteRefsCheck(t *testing.T) {
+	var refErr *RemoteRefError
+	require.ErrorAs(t, NewRemoteRefs(config.SpecRemoteRefs{}).Check("https://example.com/pet.yaml"), &refErr)
+	assert.Contains(t, refErr.Reason, "disabled")
+
+	remote := NewRemoteRefs(config.SpecRemoteRefs{Hosts: []string{"*.example.com", "api.vendor.io"}})
+	assert.NoError(t, remote.Check("https://schemas.example.com/pet.yaml"))
+	assert.NoError(t, remote.Check("https://API.vendor.io/pet.yaml"))
+	assert.ErrorContains(t, remote.Check("https://example.com/pet.yaml"), `host "example.com" is not allowed`)
+	assert.ErrorContains(t, remote.Check("http://api.vendor.io/pet.yaml"), `scheme "http" is not allowed`)
+}
+
+func TestCheckRemoteRefs(t *testing.T) {
+	spec := []byte(`openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+paths: {}
+components:
+  schemas:
+    Pet:
+      $ref: 'https://schemas.example.com/pet.yaml#/Pet'
+    Owner:
+      $ref: 'http://169.254.169.254/latest/meta-data'
+    Tag:
+      $ref: 'tag.yaml'
+`)
+
+	err := CheckRemoteRefs(spec, nil)
+	assert.ErrorContains(t, err, "remote reference https://schemas.example.com/pet.yaml is blocked")
+	assert.ErrorContains(t, err, "remote reference http://169.254.169.254/latest/meta-data is blocked")
+
+	err = CheckRemoteRefs(spec, NewRemoteRefs(config.SpecRemoteRefs{Hosts: []string{"schemas.example.com"}}))
+	assert.EqualError(t, err, `remote reference http://169.254.169.254/latest/meta-data is blocked: scheme "http" is not allowed`)
+
+	_, err = OpenDocument(spec, nil)
+	var refErr *RemoteRefError
+	assert.ErrorAs(t, err, &refErr)
+}
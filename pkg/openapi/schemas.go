@@ -0,0 +1,269 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaRename is a schema that was renamed or extracted from an inline definition
+type SchemaRename struct {
+	Old       string `json:"old"`       // Old is the upstream schema name, or the JSON pointer of the inline schema if it was extracted
+	New       string `json:"new"`       // New is the name of the schema
+	Extracted bool   `json:"extracted"` // Extracted is set if the schema was defined inline
+}
+
+// HasSchemaCustomization reports whether the customization renames or extracts schemas
+func HasSchemaCustomization(c config.CustomizationSchemas) bool {
+	return len(c.Rules) > 0 || len(c.Rename) > 0 || c.ExtractInline
+}
+
+// CustomizeSchemas renames schemas by rules and explicit names, rewriting all references, and extracts inline object schemas into named schemas
+func CustomizeSchemas(spec []byte, opts config.CustomizationSchemas) ([]byte, []SchemaRename, error) {
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prefix := "#/components/schemas/"
+	if isSwagger(root) {
+		prefix = "#/definitions/"
+	}
+
+	renames, err := renameSchemas(root, prefix, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.ExtractInline {
+		renames = append(renames, extractInlineSchemas(root, prefix)...)
+	}
+
+	output, err := renderNode(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output, renames, nil
+}
+
+// renameSchemas renames the named schemas and rewrites all references to them
+func renameSchemas(root *yaml.Node, prefix string, opts config.CustomizationSchemas) ([]SchemaRename, error) {
+	var rules []*regexp.Regexp
+	for _, rule := range opts.Rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema rename pattern %s: %w", rule.Pattern, err)
+		}
+		rules = append(rules, re)
+	}
+
+	group := schemaGroup(root)
+	if group == nil {
+		return nil, nil
+	}
+	names := map[string]string{}
+	owners := map[string]string{}
+	var renames []SchemaRename
+	for i := 0; i+1 < len(group.Content); i += 2 {
+		name := group.Content[i].Value
+		newName, ok := opts.Rename[name]
+		if !ok {
+			newName = name
+			for r, re := range rules {
+				if re.MatchString(newName) {
+					newName = re.ReplaceAllString(newName, opts.Rules[r].Replace)
+				}
+			}
+		}
+		if owner, ok := owners[newName]; ok {
+			return nil, fmt.Errorf("schemas %s and %s would both be named %s", owner, name, newName)
+		}
+		owners[newName] = name
+
+		if newName != name {
+			names[name] = newName
+			group.Content[i].Value = newName
+			renames = append(renames, SchemaRename{Old: name, New: newName})
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	// rewrite references and discriminator mappings
+	renameRef := func(ref string) string {
+		if !strings.HasPrefix(ref, prefix) {
+			return ref
+		}
+		token, rest, _ := strings.Cut(ref[len(prefix):], "/")
+		tokens, err := parsePointer("/" + token)
+		if err != nil {
+			return ref
+		}
+		if newName, ok := names[tokens[0]]; ok {
+			ref = prefix + jsonPointer(newName)[1:]
+			if rest != "" {
+				ref += "/" + rest
+			}
+		}
+		return ref
+	}
+	walkMappings(root, "", func(n *yaml.Node, parentKey string) {
+		if ref := mappingValue(n, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode && parentKey != "properties" {
+			ref.Value = renameRef(ref.Value)
+		}
+		if parentKey == "discriminator" {
+			mapping := mappingValue(n, "mapping")
+			for _, key := range mappingKeys(mapping) {
+				value := mappingValue(mapping, key)
+				if newName, ok := names[value.Value]; ok {
+					value.Value = newName
+				} else {
+					value.Value = renameRef(value.Value)
+				}
+			}
+		}
+	})
+
+	return renames, nil
+}
+
+// schemaExtractor moves inline object schemas into the named schemas
+type schemaExtractor struct {
+	root    *yaml.Node
+	prefix  string
+	renames []SchemaRename
+}
+
+// group returns the named schemas, creating them on first use
+func (e *schemaExtractor) group() *yaml.Node {
+	if group := schemaGroup(e.root); group != nil {
+		return group
+	}
+	if isSwagger(e.root) {
+		return mappingChild(e.root, "definitions")
+	}
+
+	return mappingChild(mappingChild(e.root, "components"), "schemas")
+}
+
+// extractInlineSchemas extracts inline object schemas of named schemas and operations, names are derived from the parent schema or the operationId
+func extractInlineSchemas(root *yaml.Node, prefix string) []SchemaRename {
+	e := &schemaExtractor{root: root, prefix: prefix}
+
+	// nested schemas of named schemas
+	group := schemaGroup(root)
+	groupPointer := "/" + strings.Trim(prefix[1:], "/")
+	for _, name := range mappingKeys(group) {
+		e.extractNested(mappingValue(group, name), name, groupPointer+jsonPointer(name))
+	}
+
+	// request and response schemas of operations
+	paths := mappingValue(root, "paths")
+	for _, p := range mappingKeys(paths) {
+		for _, method := range httpMethods {
+			op := mappingValue(mappingValue(paths, p), method)
+			if op == nil {
+				continue
+			}
+			id := scalarValue(mappingValue(op, "operationId"))
+			if id == "" {
+				id = deriveOperationId(method, p)
+			}
+			base := util.ToPascalCase(id)
+			pointer := jsonPointer("paths", p, method)
+
+			// swagger 2.0 body parameters
+			if parameters := mappingValue(op, "parameters"); parameters != nil {
+				for i, param := range parameters.Content {
+					if scalarValue(mappingValue(param, "in")) == "body" {
+						e.extract(param, "schema", base+"Request", pointer+jsonPointer("parameters", fmt.Sprint(i), "schema"))
+					}
+				}
+			}
+
+			// openapi 3 request bodies
+			content := mappingValue(mappingValue(op, "requestBody"), "content")
+			for _, mediaType := range mappingKeys(content) {
+				e.extract(mappingValue(content, mediaType), "schema", base+"Request", pointer+jsonPointer("requestBody", "content", mediaType, "schema"))
+			}
+
+			responses := mappingValue(op, "responses")
+			for _, code := range mappingKeys(responses) {
+				name := base + "Response"
+				if code == "default" {
+					name = base + "DefaultResponse"
+				} else if !strings.HasPrefix(code, "2") {
+					name = base + code + "Response"
+				}
+				response := mappingValue(responses, code)
+				e.extract(response, "schema", name, pointer+jsonPointer("responses", code, "schema"))
+				content := mappingValue(response, "content")
+				for _, mediaType := range mappingKeys(content) {
+					e.extract(mappingValue(content, mediaType), "schema", name, pointer+jsonPointer("responses", code, "content", mediaType, "schema"))
+				}
+			}
+		}
+	}
+
+	return e.renames
+}
+
+// extract replaces the schema at key with a reference to a named schema if it is an inline object, arrays of inline objects are extracted as items
+func (e *schemaExtractor) extract(parent *yaml.Node, key string, name string, pointer string) {
+	schema := mappingValue(parent, key)
+	if schema == nil || schema.Kind != yaml.MappingNode || mappingValue(schema, "$ref") != nil {
+		return
+	}
+	e.extractNested(schema, name, pointer)
+	if mappingValue(schema, "properties") == nil {
+		return
+	}
+
+	// identical schemas share the name, different schemas get a numeric suffix
+	group := e.group()
+	unique := name
+	for i := 2; ; i++ {
+		existing := mappingValue(group, unique)
+		if existing == nil {
+			mappingSet(group, unique, schema)
+			e.renames = append(e.renames, SchemaRename{Old: pointer, New: unique, Extracted: true})
+			break
+		}
+		if nodesEqual(existing, schema) {
+			break
+		}
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	ref := mappingNode()
+	mappingSet(ref, "$ref", stringNode(e.prefix+jsonPointer(unique)[1:]))
+	mappingSet(parent, key, ref)
+}
+
+// extractNested extracts the inline object schemas of properties, items and additional properties
+func (e *schemaExtractor) extractNested(schema *yaml.Node, name string, pointer string) {
+	properties := mappingValue(schema, "properties")
+	for _, property := range mappingKeys(properties) {
+		e.extract(properties, property, name+util.ToPascalCase(property), pointer+jsonPointer("properties", property))
+	}
+	if items := mappingValue(schema, "items"); items != nil && items.Kind == yaml.MappingNode {
+		e.extract(schema, "items", name+"Item", pointer+"/items")
+	}
+	if additional := mappingValue(schema, "additionalProperties"); additional != nil && additional.Kind == yaml.MappingNode {
+		e.extract(schema, "additionalProperties", name+"Value", pointer+"/additionalProperties")
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		if members := mappingValue(schema, key); members != nil {
+			for i := range members.Content {
+				if members.Content[i].Kind == yaml.MappingNode {
+					e.extractNested(members.Content[i], name, pointer+jsonPointer(key, fmt.Sprint(i)))
+				}
+			}
+		}
+	}
+}
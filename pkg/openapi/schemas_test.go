@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const schemaSpec = `openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+paths:
+  /pets:
+    get:
+      operationId: list_pets
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/com.vendor.v2.PetDTO'
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                owner:
+                  type: object
+                  properties:
+                    id:
+                      type: string
+          application/xml:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                owner:
+                  type: object
+                  properties:
+                    id:
+                      type: string
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/com.vendor.v2.PetDTO/properties/tag'
+components:
+  schemas:
+    com.vendor.v2.PetDTO:
+      type: object
+      discriminator:
+        propertyName: kind
+        mapping:
+          dog: com.vendor.v2.PetDTO
+      properties:
+        tag:
+          type: object
+          properties:
+            label:
+              type: string
+    Error:
+      type: object
+`
+
+func TestCustomizeSchemas(t *testing.T) {
+	output, renames, err := CustomizeSchemas([]byte(schemaSpec), config.CustomizationSchemas{
+		Rules:         []config.CustomizationSchemaRule{{Pattern: `^com\.vendor\.v2\.(.*)DTO$`, Replace: "$1"}},
+		Rename:        map[string]string{"Error": "ApiError"},
+		ExtractInline: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaRename{
+		{Old: "com.vendor.v2.PetDTO", New: "Pet"},
+		{Old: "Error", New: "ApiError"},
+		{Old: "/components/schemas/Pet/properties/tag", New: "PetTag", Extracted: true},
+		{Old: "/paths/~1pets/post/requestBody/content/application~1json/schema/properties/owner", New: "CreatePetRequestOwner", Extracted: true},
+		{Old: "/paths/~1pets/post/requestBody/content/application~1json/schema", New: "CreatePetRequest", Extracted: true},
+	}, renames)
+
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Pet", "ApiError", "PetTag", "CreatePetRequestOwner", "CreatePetRequest"}, mappingKeys(schemaGroup(root)))
+
+	items, err := pointerGet(root, "/paths/~1pets/get/responses/200/content/application~1json/schema/items/$ref")
+	assert.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/Pet", items.Value)
+	xml, err := pointerGet(root, "/paths/~1pets/post/requestBody/content/application~1xml/schema/$ref")
+	assert.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/CreatePetRequest", xml.Value)
+	mapping, err := pointerGet(root, "/components/schemas/Pet/discriminator/mapping/dog")
+	assert.NoError(t, err)
+	assert.Equal(t, "Pet", mapping.Value)
+}
+
+func TestCustomizeSchemasCollision(t *testing.T) {
+	_, _, err := CustomizeSchemas([]byte(schemaSpec), config.CustomizationSchemas{
+		Rename: map[string]string{"Error": "Pet", "com.vendor.v2.PetDTO": "Pet"},
+	})
+	assert.Error(t, err)
+}
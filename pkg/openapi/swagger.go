@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// PatchSwagger applies the customizations to a swagger 2.0 specification, the libopenapi v2 model can not be rendered so the yaml tree is modified directly
+func PatchSwagger(spec []byte, customizations config.Customization) ([]byte, error) {
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse specification: %w", err)
+	}
+	if !isSwagger(root) {
+		return nil, fmt.Errorf("specification is not a swagger 2.0 document")
+	}
+
+	// info
+	info := mappingChild(root, "info")
+	setString(info, "title", customizations.Title)
+	setString(info, "description", customizations.Description)
+	setString(info, "version", customizations.Version)
+	if customizations.Contact.Name != "" || customizations.Contact.URL != "" || customizations.Contact.Email != "" {
+		contact := mappingNode()
+		setString(contact, "name", customizations.Contact.Name)
+		setString(contact, "url", customizations.Contact.URL)
+		setString(contact, "email", customizations.Contact.Email)
+		mappingSet(info, "contact", contact)
+	}
+	if customizations.License.Name != "" || customizations.License.URL != "" {
+		license := mappingNode()
+		setString(license, "name", customizations.License.Name)
+		setString(license, "url", customizations.License.URL)
+		mappingSet(info, "license", license)
+	}
+
+	// target, the first server is used unless host, basePath or schemes are set explicitly
+	if len(customizations.Servers) > 0 {
+		u, err := url.Parse(customizations.Servers[0].URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse server url %s: %w", customizations.Servers[0].URL, err)
+		}
+		setString(root, "host", u.Host)
+		setString(root, "basePath", u.Path)
+		if u.Scheme != "" {
+			mappingSet(root, "schemes", stringSequence([]string{u.Scheme}))
+		}
+	}
+	setString(root, "host", customizations.Host)
+	setString(root, "basePath", customizations.BasePath)
+	if len(customizations.Schemes) > 0 {
+		mappingSet(root, "schemes", stringSequence(customizations.Schemes))
+	}
+
+	// security
+	if len(customizations.SecuritySchemes) > 0 {
+		definitions := mappingChild(root, "securityDefinitions")
+		for _, name := range slices.Sorted(maps.Keys(customizations.SecuritySchemes)) {
+			definition, err := securityDefinition(customizations.SecuritySchemes[name])
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert security scheme %s: %w", name, err)
+			}
+			mappingSet(definitions, name, definition)
+		}
+	}
+	if len(customizations.Security) > 0 {
+		requirements := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, requirement := range customizations.Security {
+			r := mappingNode()
+			for _, name := range slices.Sorted(maps.Keys(requirement)) {
+				mappingSet(r, name, stringSequence(requirement[name]))
+			}
+			requirements.Content = append(requirements.Content, r)
+		}
+		mappingSet(root, "security", requirements)
+	}
+
+	// prune operations, tags and schemas
+	if HasPruning(customizations) {
+		pruneNode(root, customizations)
+	}
+
+	return renderNode(root)
+}
+
+// securityDefinition converts a security scheme into a swagger 2.0 security definition
+func securityDefinition(scheme config.CustomizationSecurityScheme) (*yaml.Node, error) {
+	definition := mappingNode()
+	switch {
+	case scheme.Type == "apiKey":
+		setString(definition, "type", "apiKey")
+		setString(definition, "name", scheme.Name)
+		setString(definition, "in", scheme.In)
+	case scheme.Type == "http" && scheme.Scheme == "basic":
+		setString(definition, "type", "basic")
+	case scheme.Type == "oauth2":
+		// swagger 2.0 supports a single flow per security definition
+		var flowName string
+		var flow *config.CustomizationOAuthFlow
+		switch {
+		case scheme.Flows.Implicit != nil:
+			flowName, flow = "implicit", scheme.Flows.Implicit
+		case scheme.Flows.Password != nil:
+			flowName, flow = "password", scheme.Flows.Password
+		case scheme.Flows.ClientCredentials != nil:
+			flowName, flow = "application", scheme.Flows.ClientCredentials
+		case scheme.Flows.AuthorizationCode != nil:
+			flowName, flow = "accessCode", scheme.Flows.AuthorizationCode
+		default:
+			return nil, fmt.Errorf("oauth2 security scheme requires a flow")
+		}
+		setString(definition, "type", "oauth2")
+		setString(definition, "flow", flowName)
+		setString(definition, "authorizationUrl", flow.AuthorizationURL)
+		setString(definition, "tokenUrl", flow.TokenURL)
+		scopes := mappingNode()
+		for _, s := range slices.Sorted(maps.Keys(flow.Scopes)) {
+			mappingSet(scopes, s, stringNode(flow.Scopes[s]))
+		}
+		mappingSet(definition, "scopes", scopes)
+	default:
+		return nil, fmt.Errorf("security scheme type %s %s is not supported by swagger 2.0", scheme.Type, scheme.Scheme)
+	}
+	setString(definition, "description", scheme.Description)
+
+	return definition, nil
+}
+
+// pruneNode removes operations by operationId or glob, operations with a pruned tag and schemas from the yaml tree.
+// Path items are only removed if their last operation was pruned, items without operations are kept.
+func pruneNode(root *yaml.Node, customizations config.Customization) {
+	paths := mappingValue(root, "paths")
+	for _, p := range mappingKeys(paths) {
+		item := mappingValue(paths, p)
+		pruned := false
+		for _, method := range httpMethods {
+			op := mappingValue(item, method)
+			if op == nil {
+				continue
+			}
+			operationId := scalarValue(mappingValue(op, "operationId"))
+			tags := sequenceValues(mappingValue(op, "tags"))
+			if matchesAny(customizations.PruneOperations, operationId) || slices.ContainsFunc(tags, func(tag string) bool { return matchesAny(customizations.PruneTags, tag) }) {
+				log.Debug().Str("path", p).Str("method", method).Str("operationId", operationId).Msg("pruning operation")
+				mappingDelete(item, method)
+				pruned = true
+			}
+		}
+		if pruned && !slices.ContainsFunc(mappingKeys(item), func(key string) bool { return slices.Contains(httpMethods, key) }) {
+			mappingDelete(paths, p)
+		}
+	}
+
+	if tags := mappingValue(root, "tags"); tags != nil {
+		tags.Content = slices.DeleteFunc(tags.Content, func(tag *yaml.Node) bool {
+			return matchesAny(customizations.PruneTags, scalarValue(mappingValue(tag, "name")))
+		})
+	}
+
+	schemas := schemaGroup(root)
+	for _, name := range mappingKeys(schemas) {
+		if matchesAny(customizations.PruneSchemas, name) {
+			log.Debug().Str("schema", name).Msg("pruning schema")
+			mappingDelete(schemas, name)
+		}
+	}
+}
+
+// schemaGroup returns the node that contains the named schemas, definitions for swagger 2.0 and components/schemas for openapi 3
+func schemaGroup(root *yaml.Node) *yaml.Node {
+	if isSwagger(root) {
+		return mappingValue(root, "definitions")
+	}
+
+	return mappingValue(mappingValue(root, "components"), "schemas")
+}
+
+// mappingChild returns the mapping value of key, creating it if it is not present
+func mappingChild(node *yaml.Node, key string) *yaml.Node {
+	child := mappingValue(node, key)
+	if child == nil {
+		child = mappingNode()
+		mappingSet(node, key, child)
+	}
+
+	return child
+}
+
+// setString sets key to a string value, empty values are ignored
+func setString(node *yaml.Node, key string, value string) {
+	if value != "" {
+		mappingSet(node, key, stringNode(value))
+	}
+}
+
+// stringSequence creates a sequence node of strings
+func stringSequence(values []string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, v := range values {
+		node.Content = append(node.Content, stringNode(v))
+	}
+
+	return node
+}
+
+// scalarValue returns the value of a scalar node, or an empty string
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return node.Value
+}
+
+// sequenceValues returns the scalar values of a sequence node
+func sequenceValues(node *yaml.Node) []string {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	values := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		values = append(values, scalarValue(item))
+	}
+
+	return values
+}
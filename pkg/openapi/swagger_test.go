@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const swaggerSpecA = `swagger: "2.0"
+info:
+  title: A
+  version: "1.0.0"
+host: a.example.com
+basePath: /v1
+schemes:
+  - http
+tags:
+  - name: pets
+  - name: admin
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      responses:
+        "200":
+          description: ok
+          schema:
+            $ref: '#/definitions/Pet'
+  /admin:
+    get:
+      operationId: getAdmin
+      tags: [admin]
+      responses:
+        "200":
+          description: ok
+          schema:
+            $ref: '#/definitions/Admin'
+definitions:
+  Pet:
+    type: object
+  Admin:
+    type: object
+`
+
+const swaggerSpecB = `swagger: "2.0"
+info:
+  title: B
+  version: "2.0.0"
+host: b.example.com
+schemes:
+  - https
+paths:
+  /owners:
+    get:
+      operationId: listOwners
+      responses:
+        "200":
+          description: ok
+          schema:
+            $ref: '#/definitions/Owner'
+definitions:
+  Owner:
+    type: object
+`
+
+func TestMergeSwagger(t *testing.T) {
+	result, err := MergeAndPatch([][]byte{[]byte(swaggerSpecA), []byte(swaggerSpecB)}, PipelineOptions{})
+	assert.NoError(t, err)
+
+	root, err := parseNode(result.Spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "a.example.com", mappingValue(root, "host").Value)
+	assert.Equal(t, []string{"http", "https"}, sequenceValues(mappingValue(root, "schemes")))
+	assert.Equal(t, []string{"/pets", "/admin", "/owners"}, mappingKeys(mappingValue(root, "paths")))
+	assert.Equal(t, []string{"Pet", "Admin", "Owner"}, mappingKeys(mappingValue(root, "definitions")))
+}
+
+func TestMergeSwaggerAndOpenAPI(t *testing.T) {
+	_, err := MergeAndPatch([][]byte{[]byte(swaggerSpecA), []byte(mergeSpecA)}, PipelineOptions{})
+	assert.Error(t, err)
+}
+
+func TestPatchSwagger(t *testing.T) {
+	customization := config.Customization{
+		Title:     "Pet API",
+		Version:   "3.0.0",
+		Host:      "api.example.com",
+		BasePath:  "/v2",
+		Schemes:   []string{"https"},
+		PruneTags: []string{"admin"},
+		SecuritySchemes: map[string]config.CustomizationSecurityScheme{
+			"token": {Type: "apiKey", Name: "X-Token", In: "header"},
+		},
+	}
+	output, err := PatchSwagger([]byte(swaggerSpecA), customization)
+	assert.NoError(t, err)
+	output, removed, err := RemoveUnreferencedComponents(output, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/definitions/Admin"}, removed)
+
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	info := mappingValue(root, "info")
+	assert.Equal(t, "Pet API", mappingValue(info, "title").Value)
+	assert.Equal(t, "3.0.0", mappingValue(info, "version").Value)
+	assert.Equal(t, "api.example.com", mappingValue(root, "host").Value)
+	assert.Equal(t, "/v2", mappingValue(root, "basePath").Value)
+	assert.Equal(t, []string{"https"}, sequenceValues(mappingValue(root, "schemes")))
+	assert.Equal(t, []string{"/pets"}, mappingKeys(mappingValue(root, "paths")))
+	assert.Len(t, mappingValue(root, "tags").Content, 1)
+	assert.Equal(t, "apiKey", mappingValue(mappingValue(mappingValue(root, "securityDefinitions"), "token"), "type").Value)
+}
+
+func TestPatchSwaggerUnsupportedSecurityScheme(t *testing.T) {
+	_, err := PatchSwagger([]byte(swaggerSpecA), config.Customization{
+		SecuritySchemes: map[string]config.CustomizationSecurityScheme{
+			"oidc": {Type: "openIdConnect", OpenIdConnectURL: "https://example.com/.well-known/openid-configuration"},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestPatchSwaggerKeepsPathItemsWithoutOperations(t *testing.T) {
+	spec := strings.Replace(swaggerSpecA, "paths:\n", "paths:\n  /pets/{id}:\n    parameters:\n      - name: id\n        in: path\n        required: true\n        type: string\n", 1)
+	output, err := PatchSwagger([]byte(spec), config.Customization{PruneTags: []string{"admin"}})
+	assert.NoError(t, err)
+
+	root, err := parseNode(output)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/pets/{id}", "/pets"}, mappingKeys(mappingValue(root, "paths")))
+}
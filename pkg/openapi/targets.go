@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+)
+
+// UnmatchedTarget is a patch or customization target that does not match anything in the specification
+type UnmatchedTarget struct {
+	Step   string `json:"step"`   // Step is the patch or customization that defines the target
+	Target string `json:"target"` // Target is the pointer, JSONPath or name that matched nothing
+}
+
+// UnmatchedTargetsError is returned in strict mode if at least one target matched nothing
+type UnmatchedTargetsError struct {
+	Targets []UnmatchedTarget
+}
+
+func (e *UnmatchedTargetsError) Error() string {
+	targets := make([]string, 0, len(e.Targets))
+	for _, t := range e.Targets {
+		targets = append(targets, fmt.Sprintf("%s: %s", t.Step, t.Target))
+	}
+
+	return fmt.Sprintf("%d targets matched nothing: %s", len(e.Targets), strings.Join(targets, ", "))
+}
+
+// UnmatchedPatchTargets returns the missed targets of all patch reports
+func UnmatchedPatchTargets(reports []PatchReport) []UnmatchedTarget {
+	var result []UnmatchedTarget
+	for _, r := range reports {
+		for _, m := range r.Missed {
+			result = append(result, UnmatchedTarget{Step: r.Step(), Target: m})
+		}
+	}
+
+	return result
+}
+
+// UnmatchedCustomizationTargets returns all prune targets of the customization that match nothing in the openapi 3 or swagger 2.0 specification
+func UnmatchedCustomizationTargets(spec []byte, customizations config.Customization) ([]UnmatchedTarget, error) {
+	root, err := parseNode(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse specification: %w", err)
+	}
+
+	var operationIds []string
+	var tags []string
+	if tagList := mappingValue(root, "tags"); tagList != nil {
+		for _, tag := range tagList.Content {
+			tags = append(tags, scalarValue(mappingValue(tag, "name")))
+		}
+	}
+	paths := mappingValue(root, "paths")
+	for _, p := range mappingKeys(paths) {
+		for _, method := range httpMethods {
+			if op := mappingValue(mappingValue(paths, p), method); op != nil {
+				operationIds = append(operationIds, scalarValue(mappingValue(op, "operationId")))
+				tags = append(tags, sequenceValues(mappingValue(op, "tags"))...)
+			}
+		}
+	}
+	schemas := mappingKeys(schemaGroup(root))
+
+	var result []UnmatchedTarget
+	result = append(result, unmatchedNames("pruneOperations", customizations.PruneOperations, operationIds)...)
+	result = append(result, unmatchedNames("pruneTags", customizations.PruneTags, tags)...)
+	result = append(result, unmatchedNames("pruneSchemas", customizations.PruneSchemas, schemas)...)
+	result = append(result, unmatchedNames("schemas.rename", slices.Sorted(maps.Keys(customizations.Schemas.Rename)), schemas)...)
+	result = append(result, unmatchedNames("operationIds.rename", slices.Sorted(maps.Keys(customizations.OperationIds.Rename)), operationIds)...)
+
+	return result, nil
+}
+
+// unmatchedNames returns every target pattern that does not match any of the names
+func unmatchedNames(step string, targets []string, names []string) []UnmatchedTarget {
+	var result []UnmatchedTarget
+	for _, t := range targets {
+		if !slices.ContainsFunc(names, func(name string) bool { return matchesAny([]string{t}, name) }) {
+			result = append(result, UnmatchedTarget{Step: step, Target: t})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,20 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmatchedCustomizationTargets(t *testing.T) {
+	unmatched, err := UnmatchedCustomizationTargets([]byte(mergeSpecA), config.Customization{
+		PruneOperations: []string{"listPets", "deletePet"},
+		PruneSchemas:    []string{"Error", "Owner"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []UnmatchedTarget{
+		{Step: "pruneOperations", Target: "deletePet"},
+		{Step: "pruneSchemas", Target: "Owner"},
+	}, unmatched)
+}
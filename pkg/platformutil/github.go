@@ -0,0 +1,70 @@
+package platformutil
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v69/github"
+)
+
+// LastCommitDate returns the date of the last commit on the default branch that changed the file, ErrUnsupportedPlatform if the platform client does not support it
+func LastCommitDate(repo api.Repository, path string) (*time.Time, error) {
+	client, ok := repo.InternalClient.(*github.Client)
+	if !ok {
+		return nil, ErrUnsupportedPlatform
+	}
+
+	commits, _, err := client.Repositories.ListCommits(context.Background(), repo.Namespace, repo.Name, &github.CommitsListOptions{
+		SHA:         repo.DefaultBranch,
+		Path:        path,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of %s: %w", path, err)
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+
+	date := commits[0].GetCommit().GetCommitter().GetDate()
+	return date.GetTime(), nil
+}
+
+// MergeRequestCreated returns the creation date of the merge request, ErrUnsupportedPlatform if the platform client does not support it
+func MergeRequestCreated(repo api.Repository, mergeRequest api.MergeRequest) (*time.Time, error) {
+	client, ok := repo.InternalClient.(*github.Client)
+	if !ok {
+		return nil, ErrUnsupportedPlatform
+	}
+
+	// the merge request id is the global pull request id, not the number
+	pullRequests, _, err := client.PullRequests.List(context.Background(), repo.Namespace, repo.Name, &github.PullRequestListOptions{
+		State: "open",
+		Head:  repo.Namespace + ":" + mergeRequest.SourceBranch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	for _, pr := range pullRequests {
+		if pr.GetID() == mergeRequest.Id {
+			created := pr.GetCreatedAt()
+			return created.GetTime(), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// HasOpenMergeRequest reports whether an open merge request exists for the source branch
+func HasOpenMergeRequest(platform api.Platform, repo api.Repository, branch string) (bool, error) {
+	open := api.MergeRequestStateOpen
+	mergeRequests, err := platform.MergeRequests(repo, api.MergeRequestSearchOptions{State: &open})
+	if err != nil {
+		return false, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+
+	return slices.ContainsFunc(mergeRequests, func(mr api.MergeRequest) bool { return mr.SourceBranch == branch }), nil
+}
@@ -0,0 +1,127 @@
+package platformutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v69/github"
+)
+
+// ErrUnsupportedPlatform is returned by helpers that are not implemented for the platform of the repository
+var ErrUnsupportedPlatform = errors.New("not supported by the platform")
+
+// statusDescriptionLimit is the maximum length of a commit status description
+const statusDescriptionLimit = 140
+
+// CreateOrUpdateIssue updates the open issue with the label whose body contains the marker or creates a new labeled one, returns the issue number.
+// The label can only be set by users with triage access, so issues opened by others with a copied marker are never touched.
+func CreateOrUpdateIssue(repo api.Repository, label string, marker string, title string, body string) (int, error) {
+	client, ok := repo.InternalClient.(*github.Client)
+	if !ok {
+		return 0, ErrUnsupportedPlatform
+	}
+
+	issue, err := findIssue(client, repo, label, marker)
+	if err != nil {
+		return 0, err
+	}
+	if issue != nil {
+		_, _, err = client.Issues.Edit(context.Background(), repo.Namespace, repo.Name, issue.GetNumber(), &github.IssueRequest{
+			Title: &title,
+			Body:  &body,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to update issue #%d: %w", issue.GetNumber(), err)
+		}
+		return issue.GetNumber(), nil
+	}
+
+	created, _, err := client.Issues.Create(context.Background(), repo.Namespace, repo.Name, &github.IssueRequest{
+		Title:  &title,
+		Body:   &body,
+		Labels: &[]string{label},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create issue: %w", err)
+	}
+
+	return created.GetNumber(), nil
+}
+
+// CloseIssue comments on and closes the open issue with the label whose body contains the marker, returns the issue number or 0 if there is no open issue
+func CloseIssue(repo api.Repository, label string, marker string, comment string) (int, error) {
+	client, ok := repo.InternalClient.(*github.Client)
+	if !ok {
+		return 0, ErrUnsupportedPlatform
+	}
+
+	issue, err := findIssue(client, repo, label, marker)
+	if err != nil || issue == nil {
+		return 0, err
+	}
+	if comment != "" {
+		_, _, err = client.Issues.CreateComment(context.Background(), repo.Namespace, repo.Name, issue.GetNumber(), &github.IssueComment{Body: &comment})
+		if err != nil {
+			return 0, fmt.Errorf("failed to comment on issue #%d: %w", issue.GetNumber(), err)
+		}
+	}
+	state := "closed"
+	_, _, err = client.Issues.Edit(context.Background(), repo.Namespace, repo.Name, issue.GetNumber(), &github.IssueRequest{State: &state})
+	if err != nil {
+		return 0, fmt.Errorf("failed to close issue #%d: %w", issue.GetNumber(), err)
+	}
+
+	return issue.GetNumber(), nil
+}
+
+// findIssue returns the open issue with the label whose body contains the marker, pull requests are ignored
+func findIssue(client *github.Client, repo api.Repository, label string, marker string) (*github.Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:       "open",
+		Labels:      []string{label},
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		issues, resp, err := client.Issues.ListByRepo(context.Background(), repo.Namespace, repo.Name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, issue := range issues {
+			if !issue.IsPullRequest() && strings.Contains(issue.GetBody(), marker) {
+				return issue, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// SetCommitStatus sets a commit status with the state error, failure, pending or success
+func SetCommitStatus(repo api.Repository, commitHash string, state string, statusContext string, description string) error {
+	client, ok := repo.InternalClient.(*github.Client)
+	if !ok {
+		return ErrUnsupportedPlatform
+	}
+	if commitHash == "" {
+		return fmt.Errorf("failed to set commit status: commit hash of %s is unknown", repo.DefaultBranch)
+	}
+
+	if len(description) > statusDescriptionLimit {
+		description = description[:statusDescriptionLimit-3] + "..."
+	}
+	_, _, err := client.Repositories.CreateStatus(context.Background(), repo.Namespace, repo.Name, commitHash, &github.RepoStatus{
+		State:       &state,
+		Context:     &statusContext,
+		Description: &description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set commit status: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package platformutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v69/github"
+	"github.com/stretchr/testify/assert"
+)
+
+// issueServer is a minimal GitHub issues api
+type issueServer struct {
+	issues   []*github.Issue
+	comments []string
+}
+
+func (s *issueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/primelib/example/issues":
+		var open []*github.Issue
+		for _, issue := range s.issues {
+			if issue.GetState() == "open" && slices.ContainsFunc(issue.Labels, func(l *github.Label) bool { return l.GetName() == r.URL.Query().Get("labels") }) {
+				open = append(open, issue)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(open)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/primelib/example/issues":
+		var req github.IssueRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		issue := &github.Issue{Number: github.Ptr(len(s.issues) + 1), Title: req.Title, Body: req.Body, State: github.Ptr("open")}
+		for _, l := range req.GetLabels() {
+			issue.Labels = append(issue.Labels, &github.Label{Name: github.Ptr(l)})
+		}
+		s.issues = append(s.issues, issue)
+		_ = json.NewEncoder(w).Encode(issue)
+	case r.Method == http.MethodPatch:
+		var req github.IssueRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		issue := s.issues[len(s.issues)-1]
+		if req.Body != nil {
+			issue.Body = req.Body
+		}
+		if req.State != nil {
+			issue.State = req.State
+		}
+		_ = json.NewEncoder(w).Encode(issue)
+	case r.Method == http.MethodPost:
+		var req github.IssueComment
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.comments = append(s.comments, req.GetBody())
+		_ = json.NewEncoder(w).Encode(req)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestIssueLifecycle(t *testing.T) {
+	s := &issueServer{issues: []*github.Issue{
+		{Number: github.Ptr(1), Body: github.Ptr("<!-- marker -->"), State: github.Ptr("open"), PullRequestLinks: &github.PullRequestLinks{}, Labels: []*github.Label{{Name: github.Ptr("primelib-failure")}}},
+		{Number: github.Ptr(2), Body: github.Ptr("<!-- marker -->\ncopied by someone else"), State: github.Ptr("open")},
+	}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	repo := api.Repository{Namespace: "primelib", Name: "example", InternalClient: client}
+
+	// the pull request with the marker and the unlabeled issue with a copied marker are ignored
+	number, err := CreateOrUpdateIssue(repo, "primelib-failure", "<!-- marker -->", "PrimeLib generate failed", "<!-- marker -->\nfirst")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, number)
+	assert.Equal(t, "primelib-failure", s.issues[2].Labels[0].GetName())
+
+	number, err = CreateOrUpdateIssue(repo, "primelib-failure", "<!-- marker -->", "PrimeLib generate failed", "<!-- marker -->\nsecond")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, number)
+	assert.Len(t, s.issues, 3)
+	assert.Equal(t, "<!-- marker -->\nsecond", s.issues[2].GetBody())
+	assert.Equal(t, "<!-- marker -->\ncopied by someone else", s.issues[1].GetBody())
+
+	number, err = CloseIssue(repo, "primelib-failure", "<!-- marker -->", "resolved")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, number)
+	assert.Equal(t, "closed", s.issues[2].GetState())
+	assert.Equal(t, "open", s.issues[1].GetState())
+	assert.Equal(t, []string{"resolved"}, s.comments)
+
+	number, err = CloseIssue(repo, "primelib-failure", "<!-- marker -->", "resolved")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, number)
+}
+
+func TestUnsupportedPlatform(t *testing.T) {
+	repo := api.Repository{Namespace: "primelib", Name: "example"}
+	_, err := CreateOrUpdateIssue(repo, "primelib-failure", "marker", "title", "body")
+	assert.ErrorIs(t, err, ErrUnsupportedPlatform)
+	assert.ErrorIs(t, SetCommitStatus(repo, "abc", "failure", "primelib/generate", "failed"), ErrUnsupportedPlatform)
+	_, err = LastCommitDate(repo, "openapi.yaml")
+	assert.ErrorIs(t, err, ErrUnsupportedPlatform)
+	_, err = MergeRequestCreated(repo, api.MergeRequest{Id: 1})
+	assert.ErrorIs(t, err, ErrUnsupportedPlatform)
+}
@@ -0,0 +1,24 @@
+package platformutil
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v69/github"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// IsNotFound reports whether the error was caused by a platform api response with status 404, e.g. a missing file
+func IsNotFound(err error) bool {
+	var githubErr *github.ErrorResponse
+	if errors.As(err, &githubErr) && githubErr.Response != nil {
+		return githubErr.Response.StatusCode == http.StatusNotFound
+	}
+
+	var gitlabErr *gitlab.ErrorResponse
+	if errors.As(err, &gitlabErr) && gitlabErr.Response != nil {
+		return gitlabErr.Response.StatusCode == http.StatusNotFound
+	}
+
+	return false
+}
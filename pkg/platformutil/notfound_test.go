@@ -0,0 +1,20 @@
+package platformutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/stretchr/testify/assert"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestIsNotFound(t *testing.T) {
+	assert.False(t, IsNotFound(errors.New("connection refused")))
+	assert.True(t, IsNotFound(fmt.Errorf("failed to get file content: %w", &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}})))
+	assert.False(t, IsNotFound(fmt.Errorf("failed to get file content: %w", &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}})))
+	assert.True(t, IsNotFound(fmt.Errorf("failed to get file: %w", &gitlab.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}})))
+	assert.False(t, IsNotFound(&gitlab.ErrorResponse{Response: &http.Response{StatusCode: http.StatusInternalServerError}}))
+}
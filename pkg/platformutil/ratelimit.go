@@ -0,0 +1,43 @@
+package platformutil
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// defaultRateLimitWait is used if the platform does not tell when the rate limit resets
+const defaultRateLimitWait = time.Minute
+
+// RateLimitReset reports whether the error was caused by a platform rate limit and returns the time the limit resets
+func RateLimitReset(err error, now time.Time) (time.Time, bool) {
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.Rate.Reset.Time, true
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return now.Add(*abuseErr.RetryAfter), true
+		}
+		return now.Add(defaultRateLimitWait), true
+	}
+
+	var gitlabErr *gitlab.ErrorResponse
+	if errors.As(err, &gitlabErr) && gitlabErr.Response != nil && gitlabErr.Response.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.ParseInt(gitlabErr.Response.Header.Get("Retry-After"), 10, 64); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second), true
+		}
+		if reset, err := strconv.ParseInt(gitlabErr.Response.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(reset, 0), true
+		}
+		return now.Add(defaultRateLimitWait), true
+	}
+
+	return time.Time{}, false
+}
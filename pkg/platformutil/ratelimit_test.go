@@ -0,0 +1,41 @@
+package platformutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/stretchr/testify/assert"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestRateLimitReset(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	reset, limited := RateLimitReset(errors.New("not found"), now)
+	assert.False(t, limited)
+	assert.True(t, reset.IsZero())
+
+	githubReset := now.Add(10 * time.Minute)
+	reset, limited = RateLimitReset(fmt.Errorf("failed to list pull requests: %w", &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: githubReset}}}), now)
+	assert.True(t, limited)
+	assert.Equal(t, githubReset, reset)
+
+	retryAfter := 30 * time.Second
+	reset, limited = RateLimitReset(&github.AbuseRateLimitError{RetryAfter: &retryAfter}, now)
+	assert.True(t, limited)
+	assert.Equal(t, now.Add(retryAfter), reset)
+
+	header := http.Header{}
+	header.Set("Retry-After", "45")
+	reset, limited = RateLimitReset(&gitlab.ErrorResponse{Response: &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}}, now)
+	assert.True(t, limited)
+	assert.Equal(t, now.Add(45*time.Second), reset)
+
+	reset, limited = RateLimitReset(&gitlab.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}}}, now)
+	assert.False(t, limited)
+	assert.True(t, reset.IsZero())
+}
@@ -1,6 +1,7 @@
 package preset
 
 import (
+	"context"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -25,7 +26,7 @@ func (n *GoLibraryGenerator) GetOutputName() string {
 	return "go"
 }
 
-func (n *GoLibraryGenerator) Generate(opts generator.GenerateOptions) error {
+func (n *GoLibraryGenerator) Generate(ctx context.Context, opts generator.GenerateOptions) error {
 	moduleName := suggestGoModuleName(n.Opts.ModuleName, n.Repository, opts.ProjectDirectory, opts.OutputDirectory)
 
 	log.Info().Str("dir", opts.OutputDirectory).Str("spec", n.APISpec).Msg("generating go library")
@@ -43,7 +44,7 @@ func (n *GoLibraryGenerator) Generate(opts generator.GenerateOptions) error {
 		},
 	}
 
-	return gen.Generate(opts)
+	return gen.Generate(ctx, opts)
 }
 
 func suggestGoModuleName(moduleName string, repository config.Repository, projectDirectory string, outputDirectory string) string {
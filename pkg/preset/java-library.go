@@ -1,6 +1,7 @@
 package preset
 
 import (
+	"context"
 	"net/url"
 	"os"
 	"slices"
@@ -26,7 +27,7 @@ func (n *JavaLibraryGenerator) GetOutputName() string {
 	return "java"
 }
 
-func (n *JavaLibraryGenerator) Generate(opts generator.GenerateOptions) error {
+func (n *JavaLibraryGenerator) Generate(ctx context.Context, opts generator.GenerateOptions) error {
 	groupId, artifactId := suggestGroupAndArtifactId(n.Opts.GroupId, n.Opts.ArtifactId, n.Repository)
 
 	log.Info().Str("dir", opts.OutputDirectory).Str("spec", n.APISpec).Msg("generating java library")
@@ -45,7 +46,7 @@ func (n *JavaLibraryGenerator) Generate(opts generator.GenerateOptions) error {
 		},
 	}
 
-	return gen.Generate(opts)
+	return gen.Generate(ctx, opts)
 }
 
 func suggestGroupAndArtifactId(groupId string, artifactId string, repository config.Repository) (string, string) {
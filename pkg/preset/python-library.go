@@ -1,6 +1,8 @@
 package preset
 
 import (
+	"context"
+
 	"github.com/primelib/primecodegen-app/pkg/config"
 	"github.com/primelib/primecodegen-app/pkg/generator"
 	"github.com/rs/zerolog/log"
@@ -21,7 +23,7 @@ func (n *PythonLibraryGenerator) GetOutputName() string {
 	return "python"
 }
 
-func (n *PythonLibraryGenerator) Generate(opts generator.GenerateOptions) error {
+func (n *PythonLibraryGenerator) Generate(ctx context.Context, opts generator.GenerateOptions) error {
 	log.Info().Str("dir", opts.OutputDirectory).Str("spec", n.APISpec).Msg("generating python library")
 
 	gen := generator.OpenAPIGenerator{
@@ -54,5 +56,5 @@ func (n *PythonLibraryGenerator) Generate(opts generator.GenerateOptions) error
 		},
 	}
 
-	return gen.Generate(opts)
+	return gen.Generate(ctx, opts)
 }
@@ -1,6 +1,8 @@
 package preset
 
 import (
+	"context"
+
 	"github.com/primelib/primecodegen-app/pkg/config"
 	"github.com/primelib/primecodegen-app/pkg/generator"
 	"github.com/rs/zerolog/log"
@@ -21,7 +23,7 @@ func (n *TypeScriptLibraryGenerator) GetOutputName() string {
 	return "typescript"
 }
 
-func (n *TypeScriptLibraryGenerator) Generate(opts generator.GenerateOptions) error {
+func (n *TypeScriptLibraryGenerator) Generate(ctx context.Context, opts generator.GenerateOptions) error {
 	log.Info().Str("dir", opts.OutputDirectory).Str("spec", n.APISpec).Msg("generating python library")
 
 	gen := generator.OpenAPIGenerator{
@@ -49,5 +51,5 @@ func (n *TypeScriptLibraryGenerator) Generate(opts generator.GenerateOptions) er
 		},
 	}
 
-	return gen.Generate(opts)
+	return gen.Generate(ctx, opts)
 }
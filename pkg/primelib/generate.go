@@ -1,17 +1,50 @@
 package primelib
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/primelib/primecodegen-app/pkg/config"
 	"github.com/primelib/primecodegen-app/pkg/generator"
+	"github.com/primelib/primecodegen-app/pkg/metrics"
 	"github.com/primelib/primecodegen-app/pkg/preset"
+	"github.com/primelib/primecodegen-app/pkg/tracing"
+	"github.com/primelib/primecodegen-app/pkg/util"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func Generate(dir string, conf config.Configuration, repository api.Repository) error {
+// GeneratorResult is the outcome of a single generator run
+type GeneratorResult struct {
+	Name            string
+	OutputDirectory string
+	Duration        time.Duration
+	ExitCode        int // ExitCode of the generator process, -1 if it did not exit normally
+	Err             error
+}
+
+// GenerateOptions configures the code generation
+type GenerateOptions struct {
+	// Timeouts limits the generator processes
+	Timeouts Timeouts
+}
+
+// Generate runs all generators of the configuration, the generator processes are killed once ctx is done
+func Generate(ctx context.Context, dir string, conf config.Configuration, repository api.Repository, opts GenerateOptions) ([]GeneratorResult, error) {
+	ctx, span := tracing.Start(ctx, "generate", tracing.Repository(repository))
+	results, err := generate(ctx, dir, conf, repository, opts)
+	tracing.End(span, err)
+
+	return results, err
+}
+
+// generate runs all generators, each generator run is traced
+func generate(ctx context.Context, dir string, conf config.Configuration, repository api.Repository, opts GenerateOptions) ([]GeneratorResult, error) {
 	spec := conf.Spec
 	specFile := filepath.Join(dir, conf.Spec.File)
 	log.Debug().Strs("spec-urls", spec.UrlSlice()).Str("spec-file", specFile).Msg("processing module")
@@ -46,22 +79,50 @@ func Generate(dir string, conf config.Configuration, repository api.Repository)
 	generators := preset.Generators(specFile, conf)
 
 	// execute generators
+	var results []GeneratorResult
 	for _, gen := range generators {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
 		outputDir := filepath.Join(dir, conf.Output)
 		if conf.MultiLanguage() {
 			outputDir = filepath.Join(outputDir, gen.GetOutputName())
 		}
 
 		log.Info().Str("generator", gen.Name()).Str("projectDir", dir).Str("outputDir", outputDir).Msg("running code generator")
-		err := gen.Generate(generator.GenerateOptions{
+		genCtx, genSpan := tracing.Start(ctx, "generator", tracing.GeneratorKey.String(gen.Name()), attribute.String("primelib.output", outputDir))
+		genCtx, cancel := util.WithTimeout(genCtx, opts.Timeouts.Generator)
+		start := time.Now()
+		err := gen.Generate(genCtx, generator.GenerateOptions{
 			ProjectDirectory: dir,
 			OutputDirectory:  outputDir,
+			Logger:           log.With().Str("repository", repositoryName(repository, dir)).Str("generator", gen.Name()).Logger(),
 		})
+		cancel()
+		result := GeneratorResult{Name: gen.Name(), OutputDirectory: outputDir, Duration: time.Since(start), Err: err}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		} else if err != nil {
+			result.ExitCode = -1
+		}
+		metrics.GeneratorFinished(result.Name, result.Duration, err)
+		genSpan.SetAttributes(attribute.Int("primelib.exit_code", result.ExitCode))
+		tracing.End(genSpan, err)
+		results = append(results, result)
 		if err != nil {
-			return fmt.Errorf("failed to generate code: %w", err)
+			return results, fmt.Errorf("failed to generate code: %w", err)
 		}
-		log.Info().Str("generator", gen.Name()).Msg("code generation completed")
+		log.Info().Str("generator", gen.Name()).Dur("duration", result.Duration).Msg("code generation completed")
 	}
 
-	return nil
+	return results, nil
+}
+
+// repositoryName returns the full name of the repository, local runs use the directory instead
+func repositoryName(repository api.Repository, dir string) string {
+	if repository.Name == "" {
+		return dir
+	}
+	return repository.Namespace + "/" + repository.Name
 }
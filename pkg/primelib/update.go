@@ -1,29 +1,101 @@
 package primelib
 
 import (
+	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/metrics"
 	"github.com/primelib/primecodegen-app/pkg/openapi"
+	"github.com/primelib/primecodegen-app/pkg/platformutil"
 	"github.com/primelib/primecodegen-app/pkg/specutil"
+	"github.com/primelib/primecodegen-app/pkg/tracing"
 	"github.com/primelib/primecodegen-app/pkg/util"
 	"github.com/rs/zerolog/log"
 )
 
+// UpdateOptions configures the spec update
+type UpdateOptions struct {
+	// DryRun runs the update without writing the spec, strict mode and lint errors are not enforced
+	DryRun bool
+	// Timeouts limits the external processes of the update
+	Timeouts Timeouts
+}
+
+// DefaultFetchTimeout limits spec source downloads that are not configured by Timeouts
+const DefaultFetchTimeout = 2 * time.Minute
+
+// Timeouts limits the duration of the external processes of a run, 0 disables a timeout
+type Timeouts struct {
+	Fetch     time.Duration // Fetch limits each spec source download
+	Convert   time.Duration // Convert limits each spec conversion
+	Generator time.Duration // Generator limits each generator run
+	Diff      time.Duration // Diff limits the spec diff
+}
+
+// SourceResult is a fetched spec source
+type SourceResult struct {
+	URL    string
+	File   string
+	Digest string // Digest is the sha256 digest of the fetched content
+	Err    error
+}
+
+// UpdateResult contains details about the spec update that are relevant for reviewers
+type UpdateResult struct {
+	// Sources contains every fetched spec source, including the source that failed
+	Sources []SourceResult
+	// MergeConflicts contains all conflicts that were resolved while merging the spec sources
+	MergeConflicts []openapi.MergeConflict
+	// Patches contains the locations each patch touched or missed
+	Patches []openapi.PatchReport
+	// Unmatched contains all patch and customization targets that matched nothing
+	Unmatched []openapi.UnmatchedTarget
+	// Lint contains the findings of all enabled lint rules
+	Lint []openapi.LintFinding
+	// OperationIdRenames contains all operationIds that were changed by the normalization
+	OperationIdRenames []openapi.OperationIdRename
+	// SchemaRenames contains all renamed and extracted schemas
+	SchemaRenames []openapi.SchemaRename
+	// ConversionLosses contains everything that could not be represented in the openapi version of the spec type
+	ConversionLosses []openapi.ConversionLoss
+	// BundledRefs contains all external references that were copied into the spec
+	BundledRefs []openapi.BundledRef
+}
+
 // Update will update the openapi spec and apply patches
-func Update(dir string, conf config.Configuration, repository api.Repository) error {
+func Update(ctx context.Context, dir string, conf config.Configuration, repository api.Repository, opts UpdateOptions) (UpdateResult, error) {
+	ctx, span := tracing.Start(ctx, "update", tracing.Repository(repository))
+	phases := tracing.NewPhases(ctx)
+	result, err := update(phases, dir, conf, opts)
+	phases.End(err)
+	tracing.End(span, err)
+
+	return result, err
+}
+
+// update runs the update phases, each phase is traced
+func update(phases *tracing.Phases, dir string, conf config.Configuration, opts UpdateOptions) (UpdateResult, error) {
+	var result UpdateResult
 	spec := conf.Spec
 	specFile := filepath.Join(dir, conf.Spec.File)
 	log.Debug().Strs("spec-urls", spec.UrlSlice()).Str("spec-format", string(spec.Type)).Str("spec-file", specFile).Msg("processing module")
 
+	// remote references are only fetched from allowed hosts
+	remote := openapi.NewRemoteRefs(spec.RemoteRefs)
+
 	// download spec sources
 	targetSpecDir := spec.GetSourcesDir(dir)
 	var specFiles []string
 	var specFilesType []config.SpecType
+	var specLocations []string
 	var tempFiles []string
 	defer func() {
 		for _, f := range tempFiles {
@@ -34,26 +106,36 @@ func Update(dir string, conf config.Configuration, repository api.Repository) er
 	// download spec sources
 	for _, s := range spec.Sources {
 		log.Debug().Str("url", s.URL).Str("type", string(s.Type)).Msg("fetching spec")
+		fetchCtx := phases.Start("fetch", tracing.SourceKey.String(s.URL+s.File))
+		if err := fetchCtx.Err(); err != nil {
+			return result, err
+		}
 		var targetFile string
+		var location string
 		var bytes []byte
 		var err error
 
 		// fetch spec
 		if s.File != "" && s.URL == "" {
-			bytes, err = os.ReadFile(filepath.Join(targetSpecDir, s.File))
+			location = filepath.Join(targetSpecDir, s.File)
+			bytes, err = os.ReadFile(location)
 		} else if s.URL != "" {
-			bytes, err = fetchSpec(s)
+			location = s.URL
+			ctx, cancel := util.WithTimeout(fetchCtx, opts.Timeouts.Fetch)
+			bytes, err = fetchSpec(ctx, s)
+			cancel()
 		}
+		result.Sources = append(result.Sources, SourceResult{URL: s.URL, File: s.File, Digest: Digest(bytes), Err: err})
 		if err != nil {
-			return fmt.Errorf("failed to fetch spec: %w", err)
+			return result, fmt.Errorf("failed to fetch spec: %w", err)
 		}
 
-		if s.File != "" && s.URL != "" {
+		if s.File != "" && s.URL != "" && !opts.DryRun {
 			targetFile = filepath.Join(targetSpecDir, s.File)
 		} else {
 			tempFile, err := os.CreateTemp("", "api-spec-*.yaml")
 			if err != nil {
-				return fmt.Errorf("failed to create temp file: %w", err)
+				return result, fmt.Errorf("failed to create temp file: %w", err)
 			}
 			tempFiles = append(tempFiles, tempFile.Name())
 			targetFile = tempFile.Name()
@@ -62,77 +144,316 @@ func Update(dir string, conf config.Configuration, repository api.Repository) er
 		// write to file
 		err = os.WriteFile(targetFile, bytes, os.ModePerm)
 		if err != nil {
-			return fmt.Errorf("failed to write api spec to file: %w", err)
+			return result, fmt.Errorf("failed to write api spec to file: %w", err)
 		}
 		specFiles = append(specFiles, targetFile)
 		specFilesType = append(specFilesType, s.Type)
+		specLocations = append(specLocations, location)
 	}
 
 	// spec type conversions
+	convertCtx := phases.Start("convert")
+	if err := convertCtx.Err(); err != nil {
+		return result, err
+	}
 	for i, f := range specFiles {
 		// convert from swagger to openapi
-		if spec.Type == config.SpecTypeOpenAPI3 && specFilesType[i] == config.SpecTypeSwagger2 {
+		if spec.Type.IsOpenAPI3() && specFilesType[i] == config.SpecTypeSwagger2 {
 			log.Debug().Str("file", f).Msg("converting from swagger to openapi")
-			err := specutil.ConvertSwaggerToOpenAPI(f)
+			ctx, cancel := util.WithTimeout(convertCtx, opts.Timeouts.Convert)
+			err := specutil.ConvertSwaggerToOpenAPI(ctx, f)
+			cancel()
 			if err != nil {
-				return fmt.Errorf("failed to convert swagger to openapi: %w", err)
+				return result, fmt.Errorf("failed to convert swagger to openapi: %w", err)
 			}
 		}
+		if spec.Type == config.SpecTypeSwagger2 && specFilesType[i].IsOpenAPI3() {
+			return result, fmt.Errorf("failed to convert %s: openapi 3 sources can not be converted to swagger 2.0", f)
+		}
 	}
 
-	// openapi processing
-	if spec.Type == config.SpecTypeOpenAPI3 {
+	// openapi and swagger processing
+	if spec.Type.IsOpenAPI3() || spec.Type == config.SpecTypeSwagger2 {
 		// merge and patch
+		patchCtx := phases.Start("merge and patch")
+		if err := patchCtx.Err(); err != nil {
+			return result, err
+		}
 		log.Debug().Strs("files", specFiles).Str("output", specFile).Msg("merging and patching openapi spec")
-		_ = os.Remove(specFile)
-		err := specutil.MergeAndPatchOpenAPI(specFiles, spec.InputPatches, spec.Patches, specFile)
+		var sources [][]byte
+		for i, f := range specFiles {
+			bytes, err := os.ReadFile(f)
+			if err != nil {
+				return result, fmt.Errorf("failed to read api spec: %w", err)
+			}
+
+			// bundle external references, relative references are resolved against the source location
+			if spec.Bundle {
+				var bundled []openapi.BundledRef
+				bytes, bundled, err = openapi.Bundle(bytes, openapi.BundleOptions{
+					Location: specLocations[i],
+					Fetch:    remote.Fetch,
+				})
+				if err != nil {
+					return result, fmt.Errorf("failed to bundle api spec %s: %w", specLocations[i], err)
+				}
+				for _, b := range bundled {
+					log.Debug().Str("ref", b.Ref).Str("pointer", b.Pointer).Msg("bundled external reference")
+				}
+				result.BundledRefs = append(result.BundledRefs, bundled...)
+			}
+			sources = append(sources, bytes)
+		}
+		inputPatches, err := openapi.LoadPatches(dir, spec.InputPatches)
+		if err != nil {
+			return result, err
+		}
+		patches, err := openapi.LoadPatches(dir, spec.Patches)
+		if err != nil {
+			return result, err
+		}
+		merged, err := openapi.MergeAndPatch(sources, openapi.PipelineOptions{
+			InputPatches: inputPatches,
+			Patches:      patches,
+			Merge:        spec.Merge,
+			External: func(name string, spec []byte) ([]byte, error) {
+				ctx, cancel := util.WithTimeout(patchCtx, opts.Timeouts.Convert)
+				defer cancel()
+				return specutil.PatchOpenAPI(ctx, name, spec)
+			},
+		})
+		result.MergeConflicts = merged.Conflicts
+		result.Patches = merged.Patches
+		result.Unmatched = openapi.UnmatchedPatchTargets(merged.Patches)
+		if err != nil {
+			return result, fmt.Errorf("failed to merge and patch api spec: %w", err)
+		}
+
+		// convert to the openapi version of the spec type
+		if err := phases.Start("customize").Err(); err != nil {
+			return result, err
+		}
+		merged.Spec, result.ConversionLosses, err = openapi.ConvertOpenAPIVersion(merged.Spec, spec.Type)
 		if err != nil {
-			return err
+			return result, fmt.Errorf("failed to convert api spec to %s: %w", spec.Type, err)
+		}
+		for _, l := range result.ConversionLosses {
+			log.Warn().Str("pointer", l.Pointer).Str("message", l.Message).Msg("conversion loss")
 		}
 
 		// apply customizations
 		log.Debug().Str("file", specFile).Msg("applying customizations")
-		bytes, err := os.ReadFile(specFile)
+		unmatched, err := openapi.UnmatchedCustomizationTargets(merged.Spec, conf.Spec.Customization)
 		if err != nil {
-			return err
+			return result, fmt.Errorf("failed to check customization targets: %w", err)
+		}
+		result.Unmatched = append(result.Unmatched, unmatched...)
+		for _, u := range result.Unmatched {
+			log.Warn().Str("step", u.Step).Str("target", u.Target).Msg("target matched nothing")
+		}
+		if spec.Strict && !opts.DryRun && len(result.Unmatched) > 0 {
+			return result, &openapi.UnmatchedTargetsError{Targets: result.Unmatched}
 		}
 
-		doc, err := openapi.OpenDocument(bytes)
-		if err != nil {
-			return fmt.Errorf("failed to open document: %w", err)
+		// components that are unreferenced upstream are kept when pruning
+		var unreferenced []string
+		if openapi.HasPruning(conf.Spec.Customization) {
+			unreferenced, err = openapi.UnreferencedComponents(merged.Spec)
+			if err != nil {
+				return result, fmt.Errorf("failed to find unreferenced components: %w", err)
+			}
 		}
-		specInfo := doc.GetSpecInfo()
-		doc = openapi.PatchDocument(doc, specInfo.SpecType, specInfo.SpecFormat, specInfo.VersionNumeric, conf.Spec.Customization)
-		output, err := doc.Render()
+
+		var output []byte
+		if spec.Type == config.SpecTypeSwagger2 {
+			output, err = openapi.PatchSwagger(merged.Spec, conf.Spec.Customization)
+			if err != nil {
+				return result, fmt.Errorf("failed to customize swagger spec: %w", err)
+			}
+		} else {
+			doc, err := openapi.OpenDocument(merged.Spec, remote)
+			if err != nil {
+				return result, fmt.Errorf("failed to open document: %w", err)
+			}
+			specInfo := doc.GetSpecInfo()
+			doc, err = openapi.PatchDocument(doc, specInfo.SpecType, specInfo.SpecFormat, specInfo.VersionNumeric, conf.Spec.Customization)
+			if err != nil {
+				return result, fmt.Errorf("failed to customize api spec: %w", err)
+			}
+			output, err = doc.Render()
+			if err != nil {
+				return result, fmt.Errorf("failed to render api spec: %w", &openapi.RenderError{Err: err})
+			}
+		}
+
+		// drop components that are no longer referenced after pruning, before schema renames change their pointers
+		if openapi.HasPruning(conf.Spec.Customization) {
+			var removed []string
+			output, removed, err = openapi.RemoveUnreferencedComponents(output, unreferenced)
+			if err != nil {
+				return result, fmt.Errorf("failed to remove unreferenced components: %w", err)
+			}
+			log.Debug().Strs("components", removed).Msg("removed unreferenced components")
+		}
+
+		// normalize operationIds
+		if openapi.HasOperationIdNormalization(conf.Spec.Customization.OperationIds) {
+			output, result.OperationIdRenames, err = openapi.NormalizeOperationIds(output, conf.Spec.Customization.OperationIds)
+			if err != nil {
+				return result, fmt.Errorf("failed to normalize operationIds: %w", err)
+			}
+			for _, r := range result.OperationIdRenames {
+				log.Debug().Str("path", r.Path).Str("method", r.Method).Str("old", r.Old).Str("new", r.New).Msg("renamed operationId")
+			}
+		}
+
+		// rename and extract schemas
+		if openapi.HasSchemaCustomization(conf.Spec.Customization.Schemas) {
+			output, result.SchemaRenames, err = openapi.CustomizeSchemas(output, conf.Spec.Customization.Schemas)
+			if err != nil {
+				return result, fmt.Errorf("failed to customize schemas: %w", err)
+			}
+		}
+
+		// canonical formatting
+		if spec.Output.Canonical {
+			output, err = openapi.Canonicalize(output, openapi.CanonicalOptions{
+				JSON:            strings.EqualFold(filepath.Ext(specFile), ".json"),
+				Indent:          spec.Output.Indent,
+				TrailingNewline: spec.Output.TrailingNewline,
+			})
+			if err != nil {
+				return result, fmt.Errorf("failed to format api spec: %w", err)
+			}
+		}
+
+		// lint the customized spec, so customizations like operationId deduplication can resolve findings
+		if err := phases.Start("lint").Err(); err != nil {
+			return result, err
+		}
+		result.Lint, err = openapi.Lint(output, spec.Lint.Rules)
 		if err != nil {
-			log.Fatal().Err(err).Msg("failed to render document")
+			return result, fmt.Errorf("failed to lint api spec: %w", err)
+		}
+		for _, f := range result.Lint {
+			log.Warn().Str("rule", f.Rule).Str("severity", string(f.Severity)).Str("pointer", f.Pointer).Msg(f.Message)
+		}
+		if lintErrors := openapi.LintErrors(result.Lint); len(lintErrors) > 0 && !opts.DryRun {
+			return result, &openapi.LintError{Findings: lintErrors}
 		}
 
+		if err := phases.Start("write").Err(); err != nil {
+			return result, err
+		}
+		if opts.DryRun {
+			log.Info().Str("file", specFile).Msg("dry run, skipping write of api spec")
+			return result, nil
+		}
 		err = os.WriteFile(specFile, output, os.ModePerm)
 		if err != nil {
-			return fmt.Errorf("failed to write api spec to file: %w", err)
+			return result, fmt.Errorf("failed to write api spec to file: %w", err)
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
-// fetchSpec will download the spec from the source and merge it into the output
-func fetchSpec(source config.SpecSource) ([]byte, error) {
-	if source.Format == "" || source.Format == config.SourceTypeSpec {
-		content, err := util.DownloadString(source.URL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to download spec source: %w", err)
+// Digest returns the sha256 digest of the content, as recorded in the run report
+func Digest(content []byte) string {
+	if content == nil {
+		return ""
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+// SourceDigest fetches a remote spec source and returns the digest of its content
+func SourceDigest(ctx context.Context, source config.SpecSource) (string, error) {
+	content, err := fetchSpec(ctx, source)
+	if err != nil {
+		return "", err
+	}
+
+	return Digest(content), nil
+}
+
+// SourceModifiedSince reports whether a remote spec source changed after since, sources that do not support conditional requests are reported as modified
+func SourceModifiedSince(ctx context.Context, source config.SpecSource, since time.Time) (bool, error) {
+	return util.ModifiedSince(ctx, sourceURL(source), since)
+}
+
+// CommittedDigest returns the digest of the source file committed to the default branch, empty if there is none or it was converted by the update
+func CommittedDigest(platform api.Platform, repo api.Repository, spec config.Spec, source config.SpecSource) string {
+	sourcesDir := spec.GetSourcesDir("")
+	if source.File == "" || filepath.IsAbs(sourcesDir) || (spec.Type.IsOpenAPI3() && source.Type == config.SpecTypeSwagger2) {
+		return ""
+	}
+
+	file := filepath.ToSlash(filepath.Join(sourcesDir, source.File))
+	content, err := platform.FileContent(repo, repo.DefaultBranch, file)
+	if err != nil {
+		if !platformutil.IsNotFound(err) {
+			log.Warn().Err(err).Str("repository", repo.Namespace+"/"+repo.Name).Str("file", file).Msg("failed to get committed spec source")
 		}
+		return ""
+	}
+
+	return Digest([]byte(content))
+}
+
+// SourceState is the result of polling a remote spec source, it holds the validators for the next conditional request
+type SourceState struct {
+	ETag         string
+	LastModified string
+	Digest       string
+}
+
+// PollSource fetches a remote spec source with a conditional request, an unchanged source returns the previous state
+func PollSource(ctx context.Context, source config.SpecSource, previous SourceState) (SourceState, error) {
+	start := time.Now()
+	content, etag, lastModified, err := util.ConditionalGet(ctx, sourceURL(source), previous.ETag, previous.LastModified)
+	metrics.SpecFetched(source.URL, time.Since(start), err)
+	if err != nil {
+		return previous, fmt.Errorf("failed to poll spec source: %w", err)
+	}
+	if content == nil {
+		return previous, nil
+	}
+	spec, err := extractSpec(source, content)
+	if err != nil {
+		return previous, err
+	}
+
+	return SourceState{ETag: etag, LastModified: lastModified, Digest: Digest(spec)}, nil
+}
+
+// sourceURL returns the url that is downloaded for a remote spec source
+func sourceURL(source config.SpecSource) string {
+	if source.Format == config.SourceTypeSwaggerUI {
+		return source.URL + "/swagger-ui-init.js"
+	}
+	return source.URL
+}
+
+// fetchSpec will download the spec from the source and merge it into the output, the download is aborted once ctx is done
+func fetchSpec(ctx context.Context, source config.SpecSource) ([]byte, error) {
+	if source.Format != "" && source.Format != config.SourceTypeSpec && source.Format != config.SourceTypeSwaggerUI {
+		return nil, fmt.Errorf("unsupported source type: %s", source.Format)
+	}
+	start := time.Now()
+	content, err := util.DownloadString(ctx, sourceURL(source))
+	metrics.SpecFetched(source.URL, time.Since(start), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download spec source: %w", err)
+	}
+
+	return extractSpec(source, content)
+}
+
+// extractSpec returns the spec contained in the downloaded content of the source
+func extractSpec(source config.SpecSource, content []byte) ([]byte, error) {
+	if source.Format == "" || source.Format == config.SourceTypeSpec {
 		return content, nil
 	} else if source.Format == config.SourceTypeSwaggerUI {
-		swaggerJsUrl := source.URL + "/swagger-ui-init.js"
-		content, err := util.DownloadString(swaggerJsUrl)
-		if err != nil {
-			return nil, fmt.Errorf("failed to download spec source: %w", err)
-		}
-
-		// extract spec
 		re := regexp.MustCompile(`"swaggerDoc":([\S\s]*),[\n\s]*"customOptions"`)
 		match := re.FindStringSubmatch(string(content))
 		if len(match) < 2 {
@@ -0,0 +1,68 @@
+package primelib
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+const duplicateOperationIdSpec = `openapi: 3.0.3
+info:
+  title: A
+  version: "1.0.0"
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        default:
+          description: error
+    post:
+      operationId: listPets
+      responses:
+        default:
+          description: error
+`
+
+func TestUpdateLintsCustomizedSpec(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		deduplicate bool
+		lintErr     bool
+	}{
+		{name: "duplicate operationIds fail the lint", deduplicate: false, lintErr: true},
+		{name: "deduplicated operationIds pass the lint", deduplicate: true, lintErr: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "source.yaml"), []byte(duplicateOperationIdSpec), 0o644))
+			conf := config.Configuration{Spec: config.Spec{
+				File:    "openapi.yaml",
+				Type:    config.SpecTypeOpenAPI3,
+				Sources: []config.SpecSource{{File: "source.yaml", Type: config.SpecTypeOpenAPI3}},
+			}}
+			conf.Spec.Customization.OperationIds.Deduplicate = tc.deduplicate
+
+			result, err := Update(context.Background(), dir, conf, api.Repository{}, UpdateOptions{})
+			if tc.lintErr {
+				var lintErr *openapi.LintError
+				assert.True(t, errors.As(err, &lintErr))
+				assert.NoFileExists(t, filepath.Join(dir, "openapi.yaml"))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Empty(t, openapi.LintErrors(result.Lint))
+			assert.Len(t, result.OperationIdRenames, 1)
+			output, err := os.ReadFile(filepath.Join(dir, "openapi.yaml"))
+			assert.NoError(t, err)
+			assert.Contains(t, string(output), "operationId: listPets2")
+		})
+	}
+}
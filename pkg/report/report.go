@@ -0,0 +1,250 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/primelib/primecodegen-app/pkg/openapi"
+	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/specutil"
+)
+
+const (
+	StatusSuccess   = "success"   // the task completed and pushed its changes
+	StatusUnchanged = "unchanged" // the task completed without changes
+	StatusFailed    = "failed"    // the task returned an error
+)
+
+// Report is the machine-readable summary of a run, all methods are safe to call on a nil report
+type Report struct {
+	Command      string        `json:"command"`
+	StartedAt    time.Time     `json:"startedAt"`
+	FinishedAt   time.Time     `json:"finishedAt"`
+	Repositories []*Repository `json:"repositories"`
+
+	mu sync.Mutex
+}
+
+// Repository is the outcome of a single task for a repository
+type Repository struct {
+	Name         string                `json:"name"`
+	Task         string                `json:"task"`
+	Status       string                `json:"status"`
+	Error        string                `json:"error,omitempty"`
+	Sources      []Source              `json:"sources,omitempty"`
+	Patches      []openapi.PatchReport `json:"patches,omitempty"`
+	Lint         []openapi.LintFinding `json:"lint,omitempty"`
+	SpecDiff     *DiffSummary          `json:"specDiff,omitempty"`
+	Generators   []Generator           `json:"generators,omitempty"`
+	ChangedFiles map[string][]string   `json:"changedFiles,omitempty"` // ChangedFiles groups the changed files by output directory, files outside of all outputs are listed under "."
+	MergeRequest *MergeRequest         `json:"mergeRequest,omitempty"`
+	Tag          string                `json:"tag,omitempty"`
+}
+
+// Source is a fetched spec source
+type Source struct {
+	URL    string `json:"url,omitempty"`
+	File   string `json:"file,omitempty"`
+	Digest string `json:"digest,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DiffSummary counts the spec changes by oasdiff level
+type DiffSummary struct {
+	Total    int `json:"total"`
+	Breaking int `json:"breaking"`
+	Warning  int `json:"warning"`
+	Info     int `json:"info"`
+}
+
+// Generator is a single generator run
+type Generator struct {
+	Name       string `json:"name"`
+	Output     string `json:"output"`
+	DurationMs int64  `json:"durationMs"`
+	ExitCode   int    `json:"exitCode"`
+	Error      string `json:"error,omitempty"`
+}
+
+// MergeRequest is the pull or merge request that was created or updated
+type MergeRequest struct {
+	Branch string `json:"branch"`
+	Title  string `json:"title"`
+}
+
+// New creates a report for the command
+func New(command string) *Report {
+	return &Report{Command: command, StartedAt: time.Now(), Repositories: []*Repository{}}
+}
+
+// Load reads a report written by a previous run
+func Load(file string) (*Report, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var r Report
+	if err := json.Unmarshal(content, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", file, err)
+	}
+
+	return &r, nil
+}
+
+// Repository returns the entry for the repository and task, it is created on first use
+func (r *Report) Repository(name string, task string) *Repository {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, repo := range r.Repositories {
+		if repo.Name == name && repo.Task == task {
+			return repo
+		}
+	}
+	repo := &Repository{Name: name, Task: task}
+	r.Repositories = append(r.Repositories, repo)
+
+	return repo
+}
+
+// Find returns the first entry of the repository that recorded sources, nil if there is none
+func (r *Report) Find(name string) *Repository {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, repo := range r.Repositories {
+		if repo.Name == name && len(repo.Sources) > 0 {
+			return repo
+		}
+	}
+
+	return nil
+}
+
+// Write finishes the report and writes it as JSON, nothing is written if file is empty
+func (r *Report) Write(file string) error {
+	if r == nil || file == "" {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.FinishedAt = time.Now()
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.WriteFile(file, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// Finish sets the status, a task that did not set a status succeeded unless it returned an error
+func (r *Repository) Finish(err error) {
+	if r == nil {
+		return
+	}
+	if err != nil {
+		r.Status = StatusFailed
+		r.Error = err.Error()
+	} else if r.Status == "" {
+		r.Status = StatusSuccess
+	}
+}
+
+// AddUpdate records the sources, patches and lint findings of a spec update
+func (r *Repository) AddUpdate(result primelib.UpdateResult) {
+	if r == nil {
+		return
+	}
+	for _, s := range result.Sources {
+		source := Source{URL: s.URL, File: s.File, Digest: s.Digest, Status: "fetched"}
+		if s.Err != nil {
+			source.Status = StatusFailed
+			source.Error = s.Err.Error()
+		}
+		r.Sources = append(r.Sources, source)
+	}
+	r.Patches = append(r.Patches, result.Patches...)
+	r.Lint = append(r.Lint, result.Lint...)
+}
+
+// AddGenerators records the generator runs, output directories are stored relative to dir
+func (r *Repository) AddGenerators(dir string, results []primelib.GeneratorResult) {
+	if r == nil {
+		return
+	}
+	for _, g := range results {
+		gen := Generator{Name: g.Name, Output: relativePath(dir, g.OutputDirectory), DurationMs: g.Duration.Milliseconds(), ExitCode: g.ExitCode}
+		if g.Err != nil {
+			gen.Error = g.Err.Error()
+		}
+		r.Generators = append(r.Generators, gen)
+	}
+}
+
+// SetDiff summarizes the spec diff
+func (r *Repository) SetDiff(diff specutil.Diff) {
+	if r == nil {
+		return
+	}
+	summary := DiffSummary{Total: len(diff.OpenAPI)}
+	for _, d := range diff.OpenAPI {
+		switch d.Level {
+		case 3:
+			summary.Breaking++
+		case 2:
+			summary.Warning++
+		default:
+			summary.Info++
+		}
+	}
+	r.SpecDiff = &summary
+}
+
+// SetChangedFiles groups the changed files by the output directory of the recorded generators
+func (r *Repository) SetChangedFiles(dir string, files []string) {
+	if r == nil {
+		return
+	}
+	r.ChangedFiles = map[string][]string{}
+	for _, f := range files {
+		f = relativePath(dir, f)
+		output := "."
+		for _, g := range r.Generators {
+			if g.Output != "." && (f == g.Output || strings.HasPrefix(f, g.Output+"/")) {
+				output = g.Output
+				break
+			}
+		}
+		r.ChangedFiles[output] = append(r.ChangedFiles[output], f)
+	}
+}
+
+// relativePath returns path relative to dir, paths outside of dir are returned unchanged
+func relativePath(dir string, path string) string {
+	if dir == "" || !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
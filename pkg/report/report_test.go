@@ -0,0 +1,68 @@
+package report
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/specutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportNil(t *testing.T) {
+	var rep *Report
+	entry := rep.Repository("primelib/example", "generate")
+	assert.Nil(t, entry)
+	entry.AddUpdate(primelib.UpdateResult{})
+	entry.Finish(errors.New("failed"))
+	assert.NoError(t, rep.Write(filepath.Join(t.TempDir(), "report.json")))
+}
+
+func TestReportRepository(t *testing.T) {
+	dir := "/tmp/repo"
+	rep := New("generate")
+	entry := rep.Repository("primelib/example", "generate")
+	assert.Same(t, entry, rep.Repository("primelib/example", "generate"))
+
+	entry.AddUpdate(primelib.UpdateResult{Sources: []primelib.SourceResult{
+		{URL: "https://example.com/openapi.yaml", Digest: "sha256:abc"},
+		{URL: "https://example.com/broken.yaml", Err: errors.New("404 Not Found")},
+	}})
+	entry.AddGenerators(dir, []primelib.GeneratorResult{
+		{Name: "openapi-generator", OutputDirectory: "/tmp/repo/java", Duration: 1500 * time.Millisecond},
+		{Name: "primecodegen", OutputDirectory: "/tmp/repo/go", Duration: time.Second, ExitCode: 2, Err: errors.New("exit status 2")},
+	})
+	entry.SetDiff(specutil.Diff{OpenAPI: []specutil.OpenAPIDiff{{Level: 3}, {Level: 1}, {Level: 1}}})
+	entry.SetChangedFiles(dir, []string{"openapi.yaml", "/tmp/repo/java/pom.xml", "go/client.go"})
+	entry.Finish(nil)
+
+	assert.Equal(t, StatusSuccess, entry.Status)
+	assert.Equal(t, []Source{
+		{URL: "https://example.com/openapi.yaml", Digest: "sha256:abc", Status: "fetched"},
+		{URL: "https://example.com/broken.yaml", Status: StatusFailed, Error: "404 Not Found"},
+	}, entry.Sources)
+	assert.Equal(t, Generator{Name: "openapi-generator", Output: "java", DurationMs: 1500}, entry.Generators[0])
+	assert.Equal(t, 2, entry.Generators[1].ExitCode)
+	assert.Equal(t, &DiffSummary{Total: 3, Breaking: 1, Info: 2}, entry.SpecDiff)
+	assert.Equal(t, map[string][]string{".": {"openapi.yaml"}, "java": {"java/pom.xml"}, "go": {"go/client.go"}}, entry.ChangedFiles)
+}
+
+func TestReportWriteLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report.json")
+	rep := New("release")
+	rep.Repository("primelib/example", "release").Tag = "v0.1.0"
+	rep.Repository("primelib/other", "release").Finish(errors.New("failed to get releases"))
+	require.NoError(t, rep.Write(file))
+
+	loaded, err := Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, "release", loaded.Command)
+	require.Len(t, loaded.Repositories, 2)
+	assert.Equal(t, "v0.1.0", loaded.Repositories[0].Tag)
+	assert.Equal(t, StatusFailed, loaded.Repositories[1].Status)
+	assert.Equal(t, "failed to get releases", loaded.Repositories[1].Error)
+	assert.False(t, loaded.FinishedAt.IsZero())
+}
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// monday
+	now := time.Date(2025, 3, 10, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		next time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, 3, 10, 10, 30, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2025, 3, 11, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * 6,7", time.Date(2025, 3, 15, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1-5 2 *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC)}, // day of month or friday
+		{"@daily", time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"@every 6h", now.Add(6 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.next, s.Next(now))
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 10s", "@yearly"} {
+		_, err := ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
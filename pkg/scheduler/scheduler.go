@@ -0,0 +1,261 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/platformutil"
+	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
+	"github.com/primelib/primecodegen-app/pkg/util"
+	"github.com/primelib/primecodegen-app/pkg/webhook"
+	"github.com/rs/zerolog/log"
+)
+
+// Scheduler polls the spec sources of every repository on its schedule and queues an update job if a source digest changed
+type Scheduler struct {
+	Platform    api.Platform
+	Filter      tasks.Filter                        // Filter limits the repositories that are polled
+	Default     string                              // Default schedule of repositories without spec.schedule, empty disables polling for them
+	Enqueue     func(job webhook.Job) (bool, error) // Enqueue queues the update job
+	Timeout     time.Duration                       // Timeout limits each poll of a spec source, 0 disables the timeout
+	Concurrency int                                 // Concurrency is the number of repositories polled in parallel, defaults to 1
+
+	mu    sync.Mutex
+	repos map[string]*entry
+	poll  func(ctx context.Context, source config.SpecSource, previous primelib.SourceState) (primelib.SourceState, error)
+}
+
+// entry is the polling state of a repository
+type entry struct {
+	schedule *Schedule
+	expr     string
+	next     time.Time
+	sources  []config.SpecSource
+	states   map[string]primelib.SourceState // states by source url
+}
+
+// New creates a scheduler
+func New(platform api.Platform, filter tasks.Filter, defaultSchedule string, enqueue func(job webhook.Job) (bool, error)) *Scheduler {
+	return &Scheduler{
+		Platform:    platform,
+		Filter:      filter,
+		Default:     defaultSchedule,
+		Enqueue:     enqueue,
+		Timeout:     primelib.DefaultFetchTimeout,
+		Concurrency: 1,
+		repos:       map[string]*entry{},
+		poll:        primelib.PollSource,
+	}
+}
+
+// Load reads the schedule and sources of all selected repositories, the state of sources that did not change is kept
+func (s *Scheduler) Load(ctx context.Context, now time.Time) error {
+	repos, err := s.Platform.Repositories(api.RepositoryListOpts{})
+	if err != nil {
+		return fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	loaded := map[string]*entry{}
+	selected, _ := tasks.SelectRepositories(ctx, s.Platform, repos, s.Filter)
+	for _, repo := range selected {
+		name := repo.Namespace + "/" + repo.Name
+		content, err := s.Platform.FileContent(repo, repo.DefaultBranch, config.ConfigFileName)
+		if platformutil.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			log.Warn().Err(err).Str("repository", name).Msg("failed to get " + config.ConfigFileName + ", repository is not polled")
+			continue
+		}
+		conf, err := config.FromString(content)
+		if err != nil {
+			log.Warn().Err(err).Str("repository", name).Msg("invalid " + config.ConfigFileName + ", repository is not polled")
+			continue
+		}
+		e, err := s.configure(name, conf, now)
+		if err != nil {
+			log.Warn().Err(err).Str("repository", name).Msg("invalid spec.schedule, repository is not polled")
+			continue
+		}
+		if e != nil {
+			s.baseline(repo, conf.Spec, e)
+			loaded[name] = e
+		}
+	}
+
+	s.mu.Lock()
+	s.repos = loaded
+	s.mu.Unlock()
+	log.Info().Int("repo_count", len(loaded)).Msg("loaded upstream polling schedules")
+
+	return nil
+}
+
+// configure creates the polling state of a repository, nil if the repository has no schedule or remote sources
+func (s *Scheduler) configure(name string, conf config.Configuration, now time.Time) (*entry, error) {
+	expr := conf.Spec.Schedule
+	if expr == "" {
+		expr = s.Default
+	}
+	var sources []config.SpecSource
+	for _, source := range conf.Spec.Sources {
+		if source.URL != "" {
+			sources = append(sources, source)
+		}
+	}
+	if expr == "" || len(sources) == 0 {
+		return nil, nil
+	}
+	schedule, err := ParseSchedule(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entry{schedule: schedule, expr: expr, next: schedule.Next(now), sources: sources, states: map[string]primelib.SourceState{}}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if previous, ok := s.repos[name]; ok {
+		for _, source := range sources {
+			if state, ok := previous.states[source.URL]; ok {
+				e.states[source.URL] = state
+			}
+		}
+		if previous.expr == expr {
+			e.next = previous.next
+		}
+	}
+
+	return e, nil
+}
+
+// baseline sets the digest of the committed source file as the state of sources that were not polled yet, so the first poll detects upstream changes
+func (s *Scheduler) baseline(repo api.Repository, spec config.Spec, e *entry) {
+	for _, source := range e.sources {
+		if _, ok := e.states[source.URL]; ok {
+			continue
+		}
+		if digest := primelib.CommittedDigest(s.Platform, repo, spec, source); digest != "" {
+			e.states[source.URL] = primelib.SourceState{Digest: digest}
+		}
+	}
+}
+
+// Tick polls the sources of all repositories that are due with a pool of workers, the first poll of a source without a committed file only records its digest.
+// It returns once all due repositories are polled, Run waits for it so ticks never overlap.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var due []string
+	for name, e := range s.repos {
+		if !e.next.After(now) {
+			due = append(due, name)
+			e.next = e.schedule.Next(now)
+		}
+	}
+	s.mu.Unlock()
+	slices.Sort(due)
+
+	workers := max(min(s.Concurrency, len(due)), 1)
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				s.pollRepository(ctx, name)
+			}
+		}()
+	}
+dispatch:
+	for _, name := range due {
+		select {
+		case queue <- name:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// pollRepository polls all sources of a repository and queues an update job if a digest changed, the new states are only kept once the job is queued
+func (s *Scheduler) pollRepository(ctx context.Context, name string) {
+	s.mu.Lock()
+	e, ok := s.repos[name]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	var reasons []string
+	states := map[string]primelib.SourceState{}
+	for _, source := range e.sources {
+		s.mu.Lock()
+		previous := e.states[source.URL]
+		s.mu.Unlock()
+
+		pollCtx, cancel := util.WithTimeout(ctx, s.Timeout)
+		state, err := s.poll(pollCtx, source, previous)
+		cancel()
+		if err != nil {
+			log.Warn().Err(err).Str("repository", name).Str("url", source.URL).Msg("failed to poll spec source")
+			continue
+		}
+		states[source.URL] = state
+		if previous.Digest != "" && state.Digest != previous.Digest {
+			reasons = append(reasons, fmt.Sprintf("upstream %s changed to %s", source.URL, state.Digest))
+		}
+	}
+	if len(reasons) == 0 {
+		s.store(e, states)
+		log.Debug().Str("repository", name).Msg("upstream spec sources unchanged")
+		return
+	}
+
+	// the previous states are kept if the job can not be queued, so the next poll detects the change again
+	if _, err := s.Enqueue(webhook.Job{Repository: name, Kind: webhook.KindUpdate, Force: true, Reasons: reasons}); err != nil {
+		log.Error().Err(err).Str("repository", name).Msg("failed to queue update job")
+		return
+	}
+	s.store(e, states)
+	log.Info().Str("repository", name).Strs("reasons", reasons).Msg("queued update job for changed upstream spec")
+}
+
+// store records the polled states of a repository
+func (s *Scheduler) store(e *entry, states map[string]primelib.SourceState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for url, state := range states {
+		e.states[url] = state
+	}
+}
+
+// Run loads the repositories, polls due repositories every minute and reloads the repositories after the refresh interval, until ctx is done
+func (s *Scheduler) Run(ctx context.Context, refresh time.Duration) {
+	if err := s.Load(ctx, time.Now().UTC()); err != nil {
+		log.Error().Err(err).Msg("failed to load upstream polling schedules")
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	lastLoad := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if now.Sub(lastLoad) >= refresh {
+				if err := s.Load(ctx, now.UTC()); err != nil {
+					log.Error().Err(err).Msg("failed to reload upstream polling schedules")
+				}
+				lastLoad = now
+			}
+			s.Tick(ctx, now.UTC())
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v69/github"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
+	"github.com/primelib/primecodegen-app/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlatform lists the repositories and serves their config and committed files
+type fakePlatform struct {
+	api.Platform
+	configs map[string]string
+	files   map[string]string // files by repository name and path
+}
+
+func (p fakePlatform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, error) {
+	var repos []api.Repository
+	for name := range p.configs {
+		repos = append(repos, api.Repository{Namespace: "primelib", Name: name})
+	}
+	return repos, nil
+}
+
+func (p fakePlatform) FileContent(repository api.Repository, branch string, path string) (string, error) {
+	content, ok := p.configs[repository.Name]
+	if path != config.ConfigFileName {
+		content, ok = p.files[repository.Name+"/"+path]
+	}
+	if !ok {
+		return "", &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	}
+	return content, nil
+}
+
+func TestScheduler(t *testing.T) {
+	platform := fakePlatform{configs: map[string]string{
+		"scheduled": "name: scheduled\nspec:\n  schedule: \"0 * * * *\"\n  sources:\n    - url: https://example.com/scheduled.yaml\n",
+		"default":   "name: default\nspec:\n  sources:\n    - url: https://example.com/default.yaml\n    - file: local.yaml\n",
+		"local":     "name: local\nspec:\n  schedule: \"0 * * * *\"\n  sources:\n    - file: local.yaml\n",
+		"invalid":   "name: invalid\nspec:\n  schedule: \"every hour\"\n  sources:\n    - url: https://example.com/invalid.yaml\n",
+	}}
+	var jobs []webhook.Job
+	s := New(platform, tasks.Filter{}, "@daily", func(job webhook.Job) (bool, error) {
+		jobs = append(jobs, job)
+		return true, nil
+	})
+	digests := map[string]string{"https://example.com/scheduled.yaml": "sha256:a", "https://example.com/default.yaml": "sha256:b"}
+	var mu sync.Mutex
+	var polled []string
+	s.Concurrency = 2
+	s.poll = func(ctx context.Context, source config.SpecSource, previous primelib.SourceState) (primelib.SourceState, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		polled = append(polled, source.URL)
+		return primelib.SourceState{ETag: "etag", Digest: digests[source.URL]}, nil
+	}
+
+	now := time.Date(2025, 3, 10, 10, 17, 0, 0, time.UTC)
+	require.NoError(t, s.Load(context.Background(), now))
+	assert.Len(t, s.repos, 2)
+
+	// first poll records the digests
+	s.Tick(context.Background(), now.Add(time.Hour))
+	assert.Equal(t, []string{"https://example.com/scheduled.yaml"}, polled)
+	assert.Empty(t, jobs)
+
+	// unchanged digest
+	s.Tick(context.Background(), now.Add(2*time.Hour))
+	assert.Empty(t, jobs)
+
+	// changed digest queues an update, reloading keeps the state
+	digests["https://example.com/scheduled.yaml"] = "sha256:c"
+	require.NoError(t, s.Load(context.Background(), now.Add(2*time.Hour)))
+	s.Tick(context.Background(), now.Add(3*time.Hour))
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, "primelib/scheduled", jobs[0].Repository)
+		assert.Equal(t, webhook.KindUpdate, jobs[0].Kind)
+		assert.Equal(t, []string{"upstream https://example.com/scheduled.yaml changed to sha256:c"}, jobs[0].Reasons)
+	}
+
+	// default schedule
+	polled = nil
+	s.Tick(context.Background(), time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC))
+	assert.ElementsMatch(t, []string{"https://example.com/default.yaml", "https://example.com/scheduled.yaml"}, polled)
+}
+
+func TestSchedulerPollTimeout(t *testing.T) {
+	platform := fakePlatform{configs: map[string]string{
+		"slow": "name: slow\nspec:\n  schedule: \"0 * * * *\"\n  sources:\n    - url: https://example.com/slow.yaml\n",
+	}}
+	s := New(platform, tasks.Filter{}, "", func(job webhook.Job) (bool, error) { return true, nil })
+	s.Timeout = 50 * time.Millisecond
+	var pollErr error
+	s.poll = func(ctx context.Context, source config.SpecSource, previous primelib.SourceState) (primelib.SourceState, error) {
+		<-ctx.Done()
+		pollErr = ctx.Err()
+		return previous, ctx.Err()
+	}
+
+	now := time.Date(2025, 3, 10, 10, 17, 0, 0, time.UTC)
+	require.NoError(t, s.Load(context.Background(), now))
+	s.Tick(context.Background(), now.Add(time.Hour))
+	assert.ErrorIs(t, pollErr, context.DeadlineExceeded)
+}
+
+func TestSchedulerEnqueueError(t *testing.T) {
+	platform := fakePlatform{configs: map[string]string{
+		"failing": "name: failing\nspec:\n  schedule: \"0 * * * *\"\n  sources:\n    - url: https://example.com/failing.yaml\n",
+	}}
+	enqueueErr := errors.New("queue is full")
+	var jobs []webhook.Job
+	s := New(platform, tasks.Filter{}, "", func(job webhook.Job) (bool, error) {
+		if enqueueErr != nil {
+			return false, enqueueErr
+		}
+		jobs = append(jobs, job)
+		return true, nil
+	})
+	digest := "sha256:a"
+	s.poll = func(ctx context.Context, source config.SpecSource, previous primelib.SourceState) (primelib.SourceState, error) {
+		return primelib.SourceState{Digest: digest}, nil
+	}
+
+	now := time.Date(2025, 3, 10, 10, 17, 0, 0, time.UTC)
+	require.NoError(t, s.Load(context.Background(), now))
+	s.Tick(context.Background(), now.Add(time.Hour))
+
+	// the failed job keeps the previous digest
+	digest = "sha256:b"
+	s.Tick(context.Background(), now.Add(2*time.Hour))
+	assert.Empty(t, jobs)
+	assert.Equal(t, "sha256:a", s.repos["primelib/failing"].states["https://example.com/failing.yaml"].Digest)
+
+	// the change is queued once the queue accepts jobs again
+	enqueueErr = nil
+	s.Tick(context.Background(), now.Add(3*time.Hour))
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, []string{"upstream https://example.com/failing.yaml changed to sha256:b"}, jobs[0].Reasons)
+	}
+	assert.Equal(t, "sha256:b", s.repos["primelib/failing"].states["https://example.com/failing.yaml"].Digest)
+}
+
+func TestSchedulerCommittedBaseline(t *testing.T) {
+	platform := fakePlatform{
+		configs: map[string]string{
+			"moved":     "name: moved\nspec:\n  schedule: \"0 * * * *\"\n  sourcesDir: specs\n  sources:\n    - url: https://example.com/moved.yaml\n      file: moved.yaml\n",
+			"unchanged": "name: unchanged\nspec:\n  schedule: \"0 * * * *\"\n  sources:\n    - url: https://example.com/unchanged.yaml\n      file: unchanged.yaml\n",
+		},
+		files: map[string]string{
+			"moved/specs/moved.yaml":   "openapi: 3.0.3\n",
+			"unchanged/unchanged.yaml": "openapi: 3.1.0\n",
+		},
+	}
+	var jobs []webhook.Job
+	s := New(platform, tasks.Filter{}, "", func(job webhook.Job) (bool, error) {
+		jobs = append(jobs, job)
+		return true, nil
+	})
+	s.poll = func(ctx context.Context, source config.SpecSource, previous primelib.SourceState) (primelib.SourceState, error) {
+		return primelib.SourceState{Digest: primelib.Digest([]byte("openapi: 3.1.0\n"))}, nil
+	}
+
+	// the first poll compares against the committed source files
+	now := time.Date(2025, 3, 10, 10, 17, 0, 0, time.UTC)
+	require.NoError(t, s.Load(context.Background(), now))
+	s.Tick(context.Background(), now.Add(time.Hour))
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, "primelib/moved", jobs[0].Repository)
+	}
+}
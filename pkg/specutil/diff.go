@@ -1,6 +1,7 @@
 package specutil
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -12,7 +13,19 @@ type Diff struct {
 	OpenAPI []OpenAPIDiff
 }
 
-func DiffSpec(format string, file1 string, file2 string) (Diff, error) {
+// BreakingChanges returns the number of changes with the major level
+func (d Diff) BreakingChanges() int {
+	count := 0
+	for _, c := range d.OpenAPI {
+		if c.Level == 3 {
+			count++
+		}
+	}
+	return count
+}
+
+// DiffSpec compares two spec files, the diff tool is killed once ctx is done
+func DiffSpec(ctx context.Context, format string, file1 string, file2 string) (Diff, error) {
 	var diff = Diff{
 		OpenAPI: []OpenAPIDiff{},
 	}
@@ -27,7 +40,7 @@ func DiffSpec(format string, file1 string, file2 string) (Diff, error) {
 
 	// diff openapi
 	if format == "openapi" || format == "" {
-		d, err := DiffOpenAPI(file1, file2)
+		d, err := DiffOpenAPI(ctx, file1, file2)
 		if err != nil {
 			return diff, fmt.Errorf("failed to diff openapi: %w", err)
 		}
@@ -43,7 +56,7 @@ func DiffSpec(format string, file1 string, file2 string) (Diff, error) {
 	return diff, nil
 }
 
-func BumpVersion(format string, file1 string, file2 string, currentVersion string) (string, error) {
+func BumpVersion(ctx context.Context, format string, file1 string, file2 string, currentVersion string) (string, error) {
 	// parse current version
 	v, err := semver.NewVersion(currentVersion)
 	if err != nil {
@@ -62,7 +75,7 @@ func BumpVersion(format string, file1 string, file2 string, currentVersion strin
 
 	// diff openapi
 	if format == "openapi" || format == "" {
-		d, err := DiffOpenAPI(file1, file2)
+		d, err := DiffOpenAPI(ctx, file1, file2)
 		if err != nil {
 			return "", fmt.Errorf("failed to diff openapi: %w", err)
 		}
@@ -2,10 +2,10 @@ package specutil
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 
 	"github.com/primelib/primecodegen-app/pkg/util"
 )
@@ -21,9 +21,9 @@ type OpenAPIDiff struct {
 }
 
 // DiffOpenAPI compares two OAS files and returns the differences, calls the oasdiff cli tool to retrieve the json
-func DiffOpenAPI(file1 string, file2 string) ([]OpenAPIDiff, error) {
+func DiffOpenAPI(ctx context.Context, file1 string, file2 string) ([]OpenAPIDiff, error) {
 	// call cli
-	cmd := exec.Command("oasdiff", "changelog", file1, file2, "-f", "json", "--exclude-elements", "examples")
+	cmd := util.Command(ctx, "oasdiff", "changelog", file1, file2, "-f", "json", "--exclude-elements", "examples")
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = os.Stdout
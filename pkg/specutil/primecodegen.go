@@ -1,15 +1,18 @@
 package specutil
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 
+	"github.com/primelib/primecodegen-app/pkg/util"
 	"github.com/rs/zerolog/log"
 )
 
-func ConvertSwaggerToOpenAPI(file string) error {
-	cmd := exec.Command("primecodegen",
+// ConvertSwaggerToOpenAPI converts the swagger 2.0 file to openapi 3.0 in place, the process is killed once ctx is done
+func ConvertSwaggerToOpenAPI(ctx context.Context, file string) error {
+	cmd := util.Command(ctx, "primecodegen",
 		"--log-level", "trace",
 		"openapi-convert",
 		"--format-in", "swagger20",
@@ -31,23 +34,37 @@ func ConvertSwaggerToOpenAPI(file string) error {
 	return nil
 }
 
-func MergeAndPatchOpenAPI(files []string, inputPatches []string, patches []string, outputFile string) error {
-	args := []string{
-		"--log-level", "trace",
-		"openapi-patch",
-		"-o", outputFile,
+// PatchOpenAPI applies a patch of primecodegen by name, the process is killed once ctx is done
+func PatchOpenAPI(ctx context.Context, name string, spec []byte) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "primecodegen-patch-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
 	}
-	for _, f := range files {
-		args = append(args, "-i", f)
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.yaml")
+	output := filepath.Join(dir, "output.yaml")
+	if err = os.WriteFile(input, spec, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write api spec: %w", err)
 	}
-	for _, p := range inputPatches {
-		args = append(args, "--input-patch", p)
+
+	cmd := util.Command(ctx, "primecodegen",
+		"--log-level", "trace",
+		"openapi-patch",
+		"-i", input,
+		"-o", output,
+		"--patch", name,
+	)
+	cmd.Stderr = os.Stderr
+	log.Trace().Str("cmd", cmd.String()).Msg("calling primecodegen to patch openapi specification")
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to execute primecodegen: %w", err)
 	}
-	for _, p := range patches {
-		args = append(args, "--patch", p)
+
+	patched, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patched api spec: %w", err)
 	}
-	cmd := exec.Command("primecodegen", args...)
-	cmd.Stderr = os.Stderr
-	log.Trace().Str("cmd", cmd.String()).Msg("calling primecodegen to patch and merge openapi specifications")
-	return cmd.Run()
+
+	return patched, nil
 }
@@ -0,0 +1,144 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/platformutil"
+	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/report"
+	"github.com/primelib/primecodegen-app/pkg/tasks/codegeneration"
+	specupdate "github.com/primelib/primecodegen-app/pkg/tasks/specupdate"
+	"github.com/primelib/primecodegen-app/pkg/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	UpstreamUnchanged = "unchanged" // all remote sources match the committed source files or the digests of the previous run
+	UpstreamMoved     = "moved"     // at least one remote source changed since it was committed or since the previous run
+	UpstreamUnknown   = "unknown"   // a source is not committed and not in the previous run, or could not be fetched
+)
+
+// RepositoryStatus summarizes the generation state of a repository
+type RepositoryStatus struct {
+	Repository          string
+	Config              string            // Config is valid, missing or the validation error
+	Presets             []string          // Presets are the names of the enabled presets
+	SpecUpdated         *time.Time        // SpecUpdated is the date of the last commit that changed the spec file, nil if the platform does not provide it
+	MergeRequest        *api.MergeRequest // MergeRequest is the open generation or spec update merge request
+	MergeRequestCreated *time.Time        // MergeRequestCreated is nil if the platform does not provide it
+	LastTag             string
+	Upstream            string
+}
+
+// Collect loads the configuration of the repository through the platform api, previous is an optional report of an earlier run whose digests take precedence over the committed source files
+func Collect(ctx context.Context, platform api.Platform, repo api.Repository, previous *report.Report) RepositoryStatus {
+	name := repo.Namespace + "/" + repo.Name
+	s := RepositoryStatus{Repository: name, Config: "missing", Upstream: UpstreamUnknown}
+
+	// config
+	content, err := platform.FileContent(repo, repo.DefaultBranch, config.ConfigFileName)
+	if platformutil.IsNotFound(err) {
+		return s
+	} else if err != nil {
+		log.Warn().Err(err).Str("repository", name).Msg("failed to get " + config.ConfigFileName)
+		s.Config = err.Error()
+		return s
+	}
+	conf, err := config.FromString(content)
+	if err == nil {
+		err = conf.Validate()
+	}
+	if err != nil {
+		s.Config = err.Error()
+		return s
+	}
+	s.Config = "valid"
+	s.Presets = conf.Presets.EnabledNames()
+
+	// last spec update
+	s.SpecUpdated, err = platformutil.LastCommitDate(repo, conf.Spec.File)
+	if errors.Is(err, platformutil.ErrUnsupportedPlatform) {
+		log.Debug().Str("repository", name).Msg("last spec update is not supported by the platform")
+	} else if err != nil {
+		log.Warn().Err(err).Str("repository", name).Msg("failed to get last spec update")
+	}
+
+	// open generation or spec update merge request
+	open := api.MergeRequestStateOpen
+	mergeRequests, err := platform.MergeRequests(repo, api.MergeRequestSearchOptions{State: &open})
+	if err != nil {
+		log.Warn().Err(err).Str("repository", name).Msg("failed to list merge requests")
+	}
+	branches := []string{codegeneration.BranchName, specupdate.BranchName}
+	if i := slices.IndexFunc(mergeRequests, func(mr api.MergeRequest) bool { return slices.Contains(branches, mr.SourceBranch) }); i >= 0 {
+		s.MergeRequest = &mergeRequests[i]
+		s.MergeRequestCreated, err = platformutil.MergeRequestCreated(repo, mergeRequests[i])
+		if errors.Is(err, platformutil.ErrUnsupportedPlatform) {
+			log.Debug().Str("repository", name).Msg("merge request age is not supported by the platform")
+		} else if err != nil {
+			log.Warn().Err(err).Str("repository", name).Msg("failed to get merge request age")
+		}
+	}
+
+	// last tag
+	tags, err := platform.Tags(repo, 1)
+	if err != nil {
+		log.Warn().Err(err).Str("repository", name).Msg("failed to list tags")
+	}
+	if len(tags) > 0 {
+		s.LastTag = tags[0].Name
+	}
+
+	s.Upstream = upstreamState(ctx, platform, repo, conf, previous.Find(name))
+
+	return s
+}
+
+// upstreamState compares the current digests of the remote sources with the committed source files, digests recorded by a previous run take precedence
+func upstreamState(ctx context.Context, platform api.Platform, repo api.Repository, conf config.Configuration, previous *report.Repository) string {
+	for _, source := range conf.Spec.Sources {
+		if source.URL == "" {
+			continue
+		}
+		expected := primelib.CommittedDigest(platform, repo, conf.Spec, source)
+		if previous != nil {
+			if i := slices.IndexFunc(previous.Sources, func(s report.Source) bool { return s.URL == source.URL }); i >= 0 && previous.Sources[i].Digest != "" {
+				expected = previous.Sources[i].Digest
+			}
+		}
+		if expected == "" {
+			return UpstreamUnknown
+		}
+		fetchCtx, cancel := util.WithTimeout(ctx, primelib.DefaultFetchTimeout)
+		digest, err := primelib.SourceDigest(fetchCtx, source)
+		cancel()
+		if err != nil {
+			log.Warn().Err(err).Str("url", source.URL).Msg("failed to fetch spec source")
+			return UpstreamUnknown
+		}
+		if digest != expected {
+			return UpstreamMoved
+		}
+	}
+
+	return UpstreamUnchanged
+}
+
+// FormatAge renders the time since t in days or hours, "-" if t is nil
+func FormatAge(t *time.Time, now time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	age := now.Sub(*t)
+	if age >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+
+	return fmt.Sprintf("%dh", int(age.Hours()))
+}
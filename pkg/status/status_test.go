@@ -0,0 +1,132 @@
+package status
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v69/github"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/report"
+	"github.com/primelib/primecodegen-app/pkg/tasks/codegeneration"
+	specupdate "github.com/primelib/primecodegen-app/pkg/tasks/specupdate"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePlatform serves the config, merge requests and tags, all other methods are not implemented
+type fakePlatform struct {
+	api.Platform
+	config        string
+	mergeRequests []api.MergeRequest
+	tags          []api.Tag
+	files         map[string]string // files contains committed files other than the config
+	fileErr       error
+}
+
+func (p fakePlatform) FileContent(repository api.Repository, branch string, path string) (string, error) {
+	if p.fileErr != nil {
+		return "", p.fileErr
+	}
+	if path != config.ConfigFileName {
+		content, ok := p.files[path]
+		if !ok {
+			return "", &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+		}
+		return content, nil
+	}
+	if p.config == "" {
+		return "", &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	}
+	return p.config, nil
+}
+
+func (p fakePlatform) MergeRequests(repository api.Repository, options api.MergeRequestSearchOptions) ([]api.MergeRequest, error) {
+	return p.mergeRequests, nil
+}
+
+func (p fakePlatform) Tags(repository api.Repository, limit int) ([]api.Tag, error) {
+	return p.tags, nil
+}
+
+func TestCollect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("openapi: 3.0.3\n"))
+	}))
+	defer server.Close()
+
+	repo := api.Repository{Namespace: "primelib", Name: "example", DefaultBranch: "main"}
+	platform := fakePlatform{
+		config: fmt.Sprintf("name: example\npresets:\n  java:\n    enabled: true\n  go:\n    enabled: true\nspec:\n  type: openapi3\n  sources:\n    - url: %s/openapi.yaml\n", server.URL),
+		mergeRequests: []api.MergeRequest{
+			{Id: 1, SourceBranch: "renovate/deps"},
+			{Id: 2, SourceBranch: codegeneration.BranchName},
+		},
+		tags: []api.Tag{{Name: "v0.3.0"}},
+	}
+
+	s := Collect(context.Background(), platform, repo, nil)
+	assert.Equal(t, "valid", s.Config)
+	assert.Equal(t, []string{"go", "java"}, s.Presets)
+	assert.Equal(t, int64(2), s.MergeRequest.Id)
+	assert.Equal(t, "v0.3.0", s.LastTag)
+	assert.Equal(t, UpstreamUnknown, s.Upstream)
+	assert.Nil(t, s.SpecUpdated)
+
+	// the spec update merge request is detected as well
+	platform.mergeRequests = []api.MergeRequest{{Id: 3, SourceBranch: specupdate.BranchName}}
+	assert.Equal(t, int64(3), Collect(context.Background(), platform, repo, nil).MergeRequest.Id)
+
+	previous := report.New("generate")
+	previous.Repository("primelib/example", "generate").Sources = []report.Source{{URL: server.URL + "/openapi.yaml", Digest: "sha256:outdated"}}
+	assert.Equal(t, UpstreamMoved, Collect(context.Background(), platform, repo, previous).Upstream)
+
+	previous.Repositories[0].Sources[0].Digest = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("openapi: 3.0.3\n")))
+	assert.Equal(t, UpstreamUnchanged, Collect(context.Background(), platform, repo, previous).Upstream)
+}
+
+func TestCollectCommittedSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("openapi: 3.0.3\n"))
+	}))
+	defer server.Close()
+
+	repo := api.Repository{Namespace: "primelib", Name: "example", DefaultBranch: "main"}
+	platform := fakePlatform{
+		config: fmt.Sprintf("name: example\nspec:\n  type: openapi3\n  sourcesDir: sources\n  sources:\n    - url: %s/openapi.yaml\n      file: openapi.yaml\n", server.URL),
+		files:  map[string]string{"sources/openapi.yaml": "openapi: 3.0.3\n"},
+	}
+	assert.Equal(t, UpstreamUnchanged, Collect(context.Background(), platform, repo, nil).Upstream)
+
+	platform.files["sources/openapi.yaml"] = "openapi: 3.0.2\n"
+	assert.Equal(t, UpstreamMoved, Collect(context.Background(), platform, repo, nil).Upstream)
+
+	// the digest of a previous run takes precedence over the committed file
+	previous := report.New("update")
+	previous.Repository("primelib/example", "update").Sources = []report.Source{{URL: server.URL + "/openapi.yaml", Digest: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("openapi: 3.0.3\n")))}}
+	assert.Equal(t, UpstreamUnchanged, Collect(context.Background(), platform, repo, previous).Upstream)
+
+	delete(platform.files, "sources/openapi.yaml")
+	assert.Equal(t, UpstreamUnknown, Collect(context.Background(), platform, repo, nil).Upstream)
+}
+
+func TestCollectConfig(t *testing.T) {
+	repo := api.Repository{Namespace: "primelib", Name: "example"}
+	assert.Equal(t, "missing", Collect(context.Background(), fakePlatform{}, repo, nil).Config)
+	assert.Equal(t, "failed to get file content: 401 Bad credentials", Collect(context.Background(), fakePlatform{fileErr: errors.New("failed to get file content: 401 Bad credentials")}, repo, nil).Config)
+	assert.Equal(t, "spec.sources is required", Collect(context.Background(), fakePlatform{config: "name: example\nspec:\n  type: openapi3\n"}, repo, nil).Config)
+}
+
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "-", FormatAge(nil, now))
+	created := now.Add(-5 * time.Hour)
+	assert.Equal(t, "5h", FormatAge(&created, now))
+	created = now.Add(-73 * time.Hour)
+	assert.Equal(t, "3d", FormatAge(&created, now))
+}
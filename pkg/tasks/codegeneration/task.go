@@ -1,6 +1,7 @@
 package codegeneration
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"os"
@@ -14,17 +15,27 @@ import (
 	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
 	cp "github.com/otiai10/copy"
 	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/failure"
+	"github.com/primelib/primecodegen-app/pkg/metrics"
+	"github.com/primelib/primecodegen-app/pkg/platformutil"
 	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/report"
 	"github.com/primelib/primecodegen-app/pkg/specutil"
+	"github.com/primelib/primecodegen-app/pkg/tracing"
+	"github.com/primelib/primecodegen-app/pkg/util"
 	"github.com/rs/zerolog/log"
 )
 
-const branchName = "feat/primelib-generate"
+// BranchName is the branch of the generation pull request
+const BranchName = "feat/primelib-generate"
 
 //go:embed templates/description.gohtml
 var descriptionTemplate []byte
 
-type PrimeLibGenerateTask struct{}
+type PrimeLibGenerateTask struct {
+	Report   *report.Report    // Report collects the outcome of each repository, optional
+	Timeouts primelib.Timeouts // Timeouts limits the external processes
+}
 
 // Name returns the name of the task
 func (n PrimeLibGenerateTask) Name() string {
@@ -33,17 +44,36 @@ func (n PrimeLibGenerateTask) Name() string {
 
 // Execute runs the task
 func (n PrimeLibGenerateTask) Execute(ctx taskcommon.TaskContext) error {
+	return n.ExecuteContext(context.Background(), ctx)
+}
+
+// ExecuteContext runs the task, external processes are killed and the task stops once parent is done
+func (n PrimeLibGenerateTask) ExecuteContext(parent context.Context, ctx taskcommon.TaskContext) error {
+	spanCtx, span := tracing.Start(parent, "task "+n.Name(), tracing.Repository(ctx.Repository), tracing.TaskKey.String(n.Name()))
+	phases := tracing.NewPhases(spanCtx)
+	err := n.execute(ctx, phases)
+	phases.End(err)
+	tracing.End(span, err)
+
+	return err
+}
+
+func (n PrimeLibGenerateTask) execute(ctx taskcommon.TaskContext, phases *tracing.Phases) error {
+	logger := log.With().Str("repository", ctx.Repository.Namespace+"/"+ctx.Repository.Name).Str("task", n.Name()).Logger()
+	phases.Start("load config")
 	content, err := ctx.Platform.FileContent(ctx.Repository, ctx.Repository.DefaultBranch, config.ConfigFileName)
 	if err != nil {
-		return fmt.Errorf("failed to get primelib.yaml content: %w", err)
+		return failure.Step("load config", fmt.Errorf("failed to get primelib.yaml content: %w", err))
 	}
 
 	// load config
 	config, err := config.FromString(content)
 	if err != nil {
-		return fmt.Errorf("failed to load primelib.yaml: %w", err)
+		return failure.Step("load config", fmt.Errorf("failed to load primelib.yaml: %w", err))
 	}
 
+	entry := n.Report.Repository(ctx.Repository.Namespace+"/"+ctx.Repository.Name, n.Name())
+
 	// create temp directory (override, so we can run the modules individually)
 	tempDir, err := os.MkdirTemp("", "vcs-app-*")
 	if err != nil {
@@ -56,12 +86,16 @@ func (n PrimeLibGenerateTask) Execute(ctx taskcommon.TaskContext) error {
 	helper := simpletask.New(ctx)
 
 	// clone repository
+	cloneCtx := phases.Start("clone")
 	err = helper.Clone()
 	if err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+		return failure.Step("clone", fmt.Errorf("failed to clone repository: %w", err))
+	}
+	if err = cloneCtx.Err(); err != nil {
+		return err
 	}
 
-	branch := branchName
+	branch := BranchName
 	commitSuffix := ""
 
 	// create and checkout new branch
@@ -80,22 +114,30 @@ func (n PrimeLibGenerateTask) Execute(ctx taskcommon.TaskContext) error {
 	defer os.Remove(originalSpecFile.Name())
 
 	// update spec
-	err = primelib.Update(ctx.Directory, config, ctx.Repository)
+	updateCtx := phases.Start("update")
+	updateResult, err := primelib.Update(updateCtx, ctx.Directory, config, ctx.Repository, primelib.UpdateOptions{Timeouts: n.Timeouts})
+	entry.AddUpdate(updateResult)
 	if err != nil {
-		return fmt.Errorf("failed to update spec: %w", err)
+		return failure.Step("update spec", fmt.Errorf("failed to update spec: %w", err))
 	}
 
 	// generate
-	err = primelib.Generate(ctx.Directory, config, ctx.Repository)
+	generateCtx := phases.Start("generate")
+	generatorResults, err := primelib.Generate(generateCtx, ctx.Directory, config, ctx.Repository, primelib.GenerateOptions{Timeouts: n.Timeouts})
+	entry.AddGenerators(ctx.Directory, generatorResults)
 	if err != nil {
-		return fmt.Errorf("failed to generate: %w", err)
+		return failure.Step("generate", fmt.Errorf("failed to generate: %w", err))
 	}
 
 	// store updated spec file
-	diff, err := specutil.DiffSpec("openapi", originalSpecFile.Name(), specFile)
+	diffCtx, cancel := util.WithTimeout(phases.Start("diff"), n.Timeouts.Diff)
+	diff, err := specutil.DiffSpec(diffCtx, "openapi", originalSpecFile.Name(), specFile)
+	cancel()
 	if err != nil {
-		log.Warn().Err(err).Msg("failed to diff spec file")
+		logger.Warn().Err(err).Msg("failed to diff spec file")
 	}
+	entry.SetDiff(diff)
+	metrics.BreakingChanges(diff.BreakingChanges())
 	if len(diff.OpenAPI) > 15 {
 		diff.OpenAPI = diff.OpenAPI[:15] // limit to the first n changes, sorted by level
 	}
@@ -106,6 +148,7 @@ func (n PrimeLibGenerateTask) Execute(ctx taskcommon.TaskContext) error {
 		return fmt.Errorf("failed to get uncommitted changes: %w", err)
 	}
 	filteredChanges := filterChanges(changes)
+	entry.SetChangedFiles(ctx.Directory, filteredChanges)
 	commitMessage := fmt.Sprintf("feat: update generated code%s", commitSuffix)
 	if slices.Contains(changes, specFile) {
 		commitMessage = fmt.Sprintf("feat: update openapi spec%s", commitSuffix)
@@ -117,6 +160,12 @@ func (n PrimeLibGenerateTask) Execute(ctx taskcommon.TaskContext) error {
 		"SpecUpdated":  true,
 		"CodeUpdated":  len(filteredChanges) > 1,
 		"SpecDiff":     diff,
+		"Conflicts":    updateResult.MergeConflicts,
+		"Unmatched":    updateResult.Unmatched,
+		"Losses":       updateResult.ConversionLosses,
+		"Lint":         updateResult.Lint,
+		"Renames":      updateResult.OperationIdRenames,
+		"Schemas":      updateResult.SchemaRenames,
 		"Footer":       os.Getenv("PRIMEAPP_FOOTER_HIDE") != "true",
 		"FooterCustom": os.Getenv("PRIMEAPP_FOOTER_CUSTOM"),
 	})
@@ -126,14 +175,31 @@ func (n PrimeLibGenerateTask) Execute(ctx taskcommon.TaskContext) error {
 
 	// do not commit if only .openapi-generator/FILES changed
 	if len(filteredChanges) == 0 {
-		log.Info().Int("total-changes", len(changes)).Int("actual-changes", len(filteredChanges)).Msg("no changes detected, skipping commit and merge request")
+		logger.Info().Int("total-changes", len(changes)).Int("actual-changes", len(filteredChanges)).Msg("no changes detected, skipping commit and merge request")
+		if entry != nil {
+			entry.Status = report.StatusUnchanged
+		}
+		metrics.MergeRequest(metrics.MergeRequestSkipped)
 		return nil
 	}
 
 	// commit push and create or update merge request
+	if err = phases.Start("push").Err(); err != nil {
+		return err
+	}
+	mergeRequestAction := metrics.MergeRequestOpened
+	if exists, err := platformutil.HasOpenMergeRequest(ctx.Platform, ctx.Repository, branch); err != nil {
+		logger.Warn().Err(err).Msg("failed to check for an open merge request")
+	} else if exists {
+		mergeRequestAction = metrics.MergeRequestUpdated
+	}
 	err = helper.CommitPushAndMergeRequest(commitMessage, commitMessage, string(description), "")
 	if err != nil {
-		return fmt.Errorf("failed to commit push and create or update merge request: %w", err)
+		return failure.Step("create merge request", fmt.Errorf("failed to commit push and create or update merge request: %w", err))
+	}
+	metrics.MergeRequest(mergeRequestAction)
+	if entry != nil {
+		entry.MergeRequest = &report.MergeRequest{Branch: branch, Title: commitMessage}
 	}
 
 	return nil
@@ -154,8 +220,8 @@ func filterChanges(changes []string) []string {
 	return filtered
 }
 
-func NewTask() PrimeLibGenerateTask {
-	return PrimeLibGenerateTask{}
+func NewTask(rep *report.Report, timeouts primelib.Timeouts) PrimeLibGenerateTask {
+	return PrimeLibGenerateTask{Report: rep, Timeouts: timeouts}
 }
 
 func toModuleName(input string) string {
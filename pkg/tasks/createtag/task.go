@@ -1,17 +1,24 @@
 package createtag
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
 	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/failure"
+	"github.com/primelib/primecodegen-app/pkg/metrics"
+	"github.com/primelib/primecodegen-app/pkg/report"
+	"github.com/primelib/primecodegen-app/pkg/tracing"
 	"github.com/primelib/primecodegen-app/pkg/util"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type PrimeLibTagCreateTask struct {
+	Report *report.Report // Report collects the outcome of each repository, optional
 }
 
 // Name returns the name of the task
@@ -21,20 +28,37 @@ func (n PrimeLibTagCreateTask) Name() string {
 
 // Execute runs the task
 func (n PrimeLibTagCreateTask) Execute(ctx taskcommon.TaskContext) error {
+	return n.ExecuteContext(context.Background(), ctx)
+}
+
+// ExecuteContext runs the task, external processes are killed and the task stops once parent is done
+func (n PrimeLibTagCreateTask) ExecuteContext(parent context.Context, ctx taskcommon.TaskContext) error {
+	spanCtx, span := tracing.Start(parent, "task "+n.Name(), tracing.Repository(ctx.Repository), tracing.TaskKey.String(n.Name()))
+	phases := tracing.NewPhases(spanCtx)
+	err := n.execute(ctx, phases)
+	phases.End(err)
+	tracing.End(span, err)
+
+	return err
+}
+
+func (n PrimeLibTagCreateTask) execute(ctx taskcommon.TaskContext, phases *tracing.Phases) error {
+	logger := log.With().Str("repository", ctx.Repository.Namespace+"/"+ctx.Repository.Name).Str("task", n.Name()).Logger()
+	phases.Start("load config")
 	content, err := ctx.Platform.FileContent(ctx.Repository, ctx.Repository.DefaultBranch, config.ConfigFileName)
 	if err != nil {
-		return fmt.Errorf("failed to get primelib.yaml content: %w", err)
+		return failure.Step("load config", fmt.Errorf("failed to get primelib.yaml content: %w", err))
 	}
 
 	// load config
 	conf, err := config.FromString(content)
 	if err != nil {
-		return fmt.Errorf("failed to load primelib.yaml: %w", err)
+		return failure.Step("load config", fmt.Errorf("failed to load primelib.yaml: %w", err))
 	}
 
 	// requires modules
 	if !conf.HasGenerator() {
-		return fmt.Errorf("no generators enabeld")
+		return failure.Step("load config", fmt.Errorf("no generators enabeld"))
 	}
 
 	// skip if auto release is disabled
@@ -46,13 +70,17 @@ func (n PrimeLibTagCreateTask) Execute(ctx taskcommon.TaskContext) error {
 	*/
 
 	// check if last tag has a release
+	phases.Start("list tags")
 	tagList, err := ctx.Platform.Tags(ctx.Repository, 5)
 	if err != nil {
-		return fmt.Errorf("failed to get releases: %w", err)
+		return failure.Step("list tags", fmt.Errorf("failed to get releases: %w", err))
 	}
 	for _, release := range tagList {
 		if release.CommitHash == ctx.Repository.CommitHash {
-			log.Debug().Msg("latest commit already has a tag, skipping")
+			logger.Debug().Msg("latest commit already has a tag, skipping")
+			if entry := n.Report.Repository(ctx.Repository.Namespace+"/"+ctx.Repository.Name, n.Name()); entry != nil {
+				entry.Status = report.StatusUnchanged
+			}
 			return nil
 		}
 	}
@@ -64,7 +92,7 @@ func (n PrimeLibTagCreateTask) Execute(ctx taskcommon.TaskContext) error {
 			lastRelease = &tag
 		}
 	}
-	log.Debug().Interface("tag", lastRelease).Msg("found last tag")
+	logger.Debug().Interface("tag", lastRelease).Msg("found last tag")
 
 	// get next version
 	nextVersion := []string{"0.1.0"}
@@ -112,15 +140,20 @@ func (n PrimeLibTagCreateTask) Execute(ctx taskcommon.TaskContext) error {
 	version := util.FindHighestVersion(nextVersion)
 
 	// create tag
+	phases.Start("push", attribute.String("primelib.tag", "v"+version))
 	err = ctx.Platform.CreateTag(ctx.Repository, "v"+version, ctx.Repository.CommitHash, "")
 	if err != nil {
-		return fmt.Errorf("failed to create tag: %w", err)
+		return failure.Step("push", fmt.Errorf("failed to create tag: %w", err))
+	}
+	metrics.TagCreated()
+	logger.Info().Str("tag", "v"+version).Msg("created tag")
+	if entry := n.Report.Repository(ctx.Repository.Namespace+"/"+ctx.Repository.Name, n.Name()); entry != nil {
+		entry.Tag = "v" + version
 	}
-	log.Info().Str("repository", ctx.Repository.Namespace+"/"+ctx.Repository.Name).Str("tag", "v"+version).Msg("created tag")
 
 	return nil
 }
 
-func NewTask() PrimeLibTagCreateTask {
-	return PrimeLibTagCreateTask{}
+func NewTask(rep *report.Report) PrimeLibTagCreateTask {
+	return PrimeLibTagCreateTask{Report: rep}
 }
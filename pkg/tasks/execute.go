@@ -0,0 +1,296 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
+	"github.com/primelib/primecodegen-app/pkg/failure"
+	"github.com/primelib/primecodegen-app/pkg/metrics"
+	"github.com/primelib/primecodegen-app/pkg/openapi"
+	"github.com/primelib/primecodegen-app/pkg/platformutil"
+	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/report"
+	"github.com/primelib/primecodegen-app/pkg/util"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Failure is a task that failed for a repository
+type Failure struct {
+	Repository string
+	Task       string
+	Err        error
+}
+
+// FailuresError is returned if at least one task failed, all other repositories are still processed
+type FailuresError struct {
+	Failures []Failure
+}
+
+func (e *FailuresError) Error() string {
+	repos := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		repos = append(repos, fmt.Sprintf("%s (%s)", f.Repository, f.Task))
+	}
+
+	return fmt.Sprintf("%d task executions failed: %s", len(e.Failures), strings.Join(repos, ", "))
+}
+
+const (
+	// maxRateLimitRetries is the number of times a task is retried after it hit a platform rate limit
+	maxRateLimitRetries = 2
+	// maxRateLimitWait caps the pause after a rate limit, so a wrong reset time can not block the run
+	maxRateLimitWait = time.Hour
+)
+
+// cancelGracePeriod is the time a cancelled task gets to kill its processes and remove its temp files
+var cancelGracePeriod = 30 * time.Second
+
+// ContextTask is a task that can be cancelled, the context carries the cancellation of the run and the task timeout
+type ContextTask interface {
+	taskcommon.Task
+	ExecuteContext(parent context.Context, ctx taskcommon.TaskContext) error
+}
+
+// ExecuteOptions configures the task execution
+type ExecuteOptions struct {
+	Filter      Filter            // Filter selects the repositories
+	Report      *report.Report    // Report collects the outcome of each repository, optional
+	Concurrency int               // Concurrency is the number of repositories processed in parallel, defaults to 1
+	Timeout     time.Duration     // Timeout limits the duration of each task per repository, 0 disables the timeout
+	Steps       primelib.Timeouts // Steps limits the external processes of each task
+	Failures    *failure.Reporter // Failures reports failed and recovered tasks to the repository, optional
+}
+
+// ExecuteTasks runs all tasks for every selected repository of the platform, a failing repository is reported and does not stop the others
+func ExecuteTasks(ctx context.Context, platform api.Platform, tasks []taskcommon.Task, opts ExecuteOptions) error {
+	repos, err := platform.Repositories(api.RepositoryListOpts{
+		IncludeBranches:   true,
+		IncludeCommitHash: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list repositories: %w", err)
+	}
+	repos, failures := SelectRepositories(ctx, platform, repos, opts.Filter)
+	for _, f := range failures {
+		opts.Report.Repository(f.Repository, f.Task).Finish(f.Err)
+	}
+
+	err = RunTasks(ctx, platform, repos, tasks, opts)
+	if len(failures) == 0 {
+		return err
+	}
+	var failuresErr *FailuresError
+	if errors.As(err, &failuresErr) {
+		failures = append(failures, failuresErr.Failures...)
+	} else if err != nil {
+		return err
+	}
+
+	return &FailuresError{Failures: failures}
+}
+
+// RunTasks runs all tasks for the repositories, the filter of the options is not applied.
+// Once ctx is done no further repositories are started and running tasks are cancelled.
+func RunTasks(ctx context.Context, platform api.Platform, repos []api.Repository, tasks []taskcommon.Task, opts ExecuteOptions) error {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	var taskNames []string
+	for _, task := range tasks {
+		taskNames = append(taskNames, task.Name())
+	}
+	log.Info().Int("repo_count", len(repos)).Strs("tasks", taskNames).Int("concurrency", opts.Concurrency).Dur("timeout", opts.Timeout).Msg("executing tasks")
+
+	// worker pool, each repository runs all tasks in order
+	var (
+		mu       sync.Mutex
+		failures []Failure
+		wg       sync.WaitGroup
+		limiter  rateLimiter
+	)
+	queue := make(chan api.Repository)
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range queue {
+				for _, task := range tasks {
+					f := Failure{Repository: repo.Namespace + "/" + repo.Name, Task: task.Name()}
+					logger := log.With().Str("repository", f.Repository).Str("task", f.Task).Logger()
+
+					if ctx.Err() != nil {
+						break
+					}
+					f.Err = executeWithRetry(ctx, platform, task, repo, opts.Timeout, &limiter, logger)
+					if ctx.Err() != nil {
+						// the run was cancelled, this is not a failure of the repository
+						logger.Warn().Err(f.Err).Msg("task cancelled")
+						break
+					}
+					opts.Report.Repository(f.Repository, f.Task).Finish(f.Err)
+					metrics.RepositoryProcessed(f.Task, f.Err)
+					if err := opts.Failures.Report(platform, repo, f.Task, f.Err, logger); err != nil {
+						logger.Warn().Err(err).Msg("failed to report task result to the platform")
+					}
+					if f.Err != nil {
+						logger.Error().Err(f.Err).Interface("locations", openapi.ErrorLocations(f.Err)).Msg("task failed, continuing with the next repository")
+						mu.Lock()
+						failures = append(failures, f)
+						mu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+dispatch:
+	for _, repo := range repos {
+		select {
+		case queue <- repo:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		log.Warn().Err(err).Msg("task execution cancelled")
+		return fmt.Errorf("task execution cancelled: %w", err)
+	}
+
+	// summary
+	if len(failures) > 0 {
+		slices.SortFunc(failures, func(a, b Failure) int {
+			return strings.Compare(a.Repository+"/"+a.Task, b.Repository+"/"+b.Task)
+		})
+		log.Error().Int("repo_count", len(repos)).Int("failure_count", len(failures)).Msg("task execution finished with failures")
+		for _, f := range failures {
+			log.Error().Str("repository", f.Repository).Str("task", f.Task).Msg(f.Err.Error())
+		}
+		return &FailuresError{Failures: failures}
+	}
+	log.Info().Int("repo_count", len(repos)).Msg("task execution finished")
+
+	return nil
+}
+
+// executeWithRetry runs a task and retries it after the platform rate limit resets, all workers pause until the reset
+func executeWithRetry(ctx context.Context, platform api.Platform, task taskcommon.Task, repo api.Repository, timeout time.Duration, limiter *rateLimiter, logger zerolog.Logger) error {
+	for attempt := 0; ; attempt++ {
+		limiter.wait(ctx, logger)
+
+		err := executeWithTimeout(ctx, platform, task, repo, timeout)
+		reset, limited := platformutil.RateLimitReset(err, time.Now())
+		if !limited || attempt >= maxRateLimitRetries {
+			return err
+		}
+		logger.Warn().Err(err).Time("reset", reset).Msg("platform rate limit reached, pausing all workers")
+		limiter.pause(reset)
+	}
+}
+
+// executeWithTimeout runs a single task, a task that exceeds the timeout or whose context is done is reported as failed.
+// A ContextTask is cancelled and gets cancelGracePeriod to clean up, other tasks finish in the background because they can not be cancelled.
+func executeWithTimeout(ctx context.Context, platform api.Platform, task taskcommon.Task, repo api.Repository, timeout time.Duration) error {
+	taskCtx, cancel := util.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- executeTask(taskCtx, platform, task, repo)
+	}()
+	select {
+	case err := <-done:
+		return timeoutError(taskCtx, timeout, err)
+	case <-taskCtx.Done():
+	}
+
+	if _, ok := task.(ContextTask); ok {
+		select {
+		case err := <-done:
+			return timeoutError(taskCtx, timeout, err)
+		case <-time.After(cancelGracePeriod):
+		}
+	}
+	if errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("task timed out after %s", timeout)
+	}
+	return fmt.Errorf("task cancelled: %w", taskCtx.Err())
+}
+
+// timeoutError adds the timeout to the error of a task that stopped because its deadline was exceeded
+func timeoutError(taskCtx context.Context, timeout time.Duration, err error) error {
+	if err != nil && errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("task timed out after %s: %w", timeout, err)
+	}
+	return err
+}
+
+// executeTask runs a single task in its own temp directory, panics are returned as errors so they do not abort the remaining repositories
+func executeTask(ctx context.Context, platform api.Platform, task taskcommon.Task, repo api.Repository) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+
+	tempDir, err := os.MkdirTemp("", "primelib-"+tempDirNameRegex.ReplaceAllString(repo.Namespace+"-"+repo.Name, "_")+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to prepare temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	taskCtx := taskcommon.TaskContext{
+		Directory:  tempDir,
+		Platform:   platform,
+		Repository: repo,
+	}
+	if contextTask, ok := task.(ContextTask); ok {
+		return contextTask.ExecuteContext(ctx, taskCtx)
+	}
+	return task.Execute(taskCtx)
+}
+
+var tempDirNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// rateLimiter pauses all workers until the platform rate limit resets
+type rateLimiter struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// pause blocks new tasks until the reset time, capped by maxRateLimitWait
+func (r *rateLimiter) pause(reset time.Time) {
+	if limit := time.Now().Add(maxRateLimitWait); reset.After(limit) {
+		reset = limit
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reset.After(r.until) {
+		r.until = reset
+	}
+}
+
+// wait blocks until the rate limit reset or ctx is done
+func (r *rateLimiter) wait(ctx context.Context, logger zerolog.Logger) {
+	r.mu.Lock()
+	until := r.until
+	r.mu.Unlock()
+	if d := time.Until(until); d > 0 {
+		logger.Info().Dur("wait", d).Msg("waiting for platform rate limit reset")
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+		}
+	}
+}
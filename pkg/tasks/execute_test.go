@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
+	"github.com/stretchr/testify/assert"
+)
+
+// repositoryPlatform lists the repositories and serves a config for each of them
+type repositoryPlatform struct {
+	configPlatform
+	repos []api.Repository
+}
+
+func (p repositoryPlatform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, error) {
+	return p.repos, nil
+}
+
+// fakeTask runs fn for each repository and records the temp directories
+type fakeTask struct {
+	mu   sync.Mutex
+	dirs map[string]string
+	fn   func(repo api.Repository) error
+}
+
+func (t *fakeTask) Name() string {
+	return "fake"
+}
+
+func (t *fakeTask) Execute(ctx taskcommon.TaskContext) error {
+	t.mu.Lock()
+	t.dirs[ctx.Repository.Name] = ctx.Directory
+	t.mu.Unlock()
+	return t.fn(ctx.Repository)
+}
+
+func TestExecuteTasks(t *testing.T) {
+	platform := repositoryPlatform{
+		configPlatform: configPlatform{configs: map[string]string{}},
+	}
+	for _, name := range []string{"ok", "failing", "panicking", "slow", "ok2"} {
+		platform.repos = append(platform.repos, api.Repository{Namespace: "primelib", Name: name})
+		platform.configs["primelib/"+name] = "name: " + name + "\n"
+	}
+	task := &fakeTask{dirs: map[string]string{}, fn: func(repo api.Repository) error {
+		switch repo.Name {
+		case "failing":
+			return errors.New("generator failed")
+		case "panicking":
+			panic("unexpected")
+		case "slow":
+			time.Sleep(time.Second)
+		}
+		return nil
+	}}
+
+	err := ExecuteTasks(context.Background(), platform, []taskcommon.Task{task}, ExecuteOptions{Concurrency: 3, Timeout: 200 * time.Millisecond})
+	var failuresErr *FailuresError
+	assert.ErrorAs(t, err, &failuresErr)
+	if assert.Len(t, failuresErr.Failures, 3) {
+		assert.Equal(t, "primelib/failing", failuresErr.Failures[0].Repository)
+		assert.EqualError(t, failuresErr.Failures[0].Err, "generator failed")
+		assert.Equal(t, "primelib/panicking", failuresErr.Failures[1].Repository)
+		assert.EqualError(t, failuresErr.Failures[1].Err, "task panicked: unexpected")
+		assert.Equal(t, "primelib/slow", failuresErr.Failures[2].Repository)
+		assert.EqualError(t, failuresErr.Failures[2].Err, "task timed out after 200ms")
+	}
+
+	// every repository ran in its own temp directory, which is removed after the task
+	task.mu.Lock()
+	defer task.mu.Unlock()
+	assert.Len(t, task.dirs, 5)
+	assert.NotEqual(t, task.dirs["ok"], task.dirs["ok2"])
+	_, statErr := os.Stat(task.dirs["ok"])
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+// cancellableTask blocks until its context is done and records the temp directory
+type cancellableTask struct {
+	started chan string
+}
+
+func (t *cancellableTask) Name() string {
+	return "cancellable"
+}
+
+func (t *cancellableTask) Execute(ctx taskcommon.TaskContext) error {
+	return t.ExecuteContext(context.Background(), ctx)
+}
+
+func (t *cancellableTask) ExecuteContext(parent context.Context, ctx taskcommon.TaskContext) error {
+	t.started <- ctx.Directory
+	<-parent.Done()
+	return parent.Err()
+}
+
+func TestRunTasksCancelled(t *testing.T) {
+	platform := repositoryPlatform{configPlatform: configPlatform{configs: map[string]string{}}}
+	repos := []api.Repository{{Namespace: "primelib", Name: "first"}, {Namespace: "primelib", Name: "second"}}
+	task := &cancellableTask{started: make(chan string, 2)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-task.started
+		cancel()
+	}()
+	err := RunTasks(ctx, platform, repos, []taskcommon.Task{task}, ExecuteOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, task.started, 0, "no repository is started after the cancellation")
+
+	var failuresErr *FailuresError
+	assert.False(t, errors.As(err, &failuresErr), "a cancelled run is not reported as a repository failure")
+}
+
+func TestRunTasksContextTimeout(t *testing.T) {
+	platform := repositoryPlatform{configPlatform: configPlatform{configs: map[string]string{}}}
+	task := &cancellableTask{started: make(chan string, 1)}
+
+	err := RunTasks(context.Background(), platform, []api.Repository{{Namespace: "primelib", Name: "slow"}}, []taskcommon.Task{task}, ExecuteOptions{Timeout: 100 * time.Millisecond})
+	var failuresErr *FailuresError
+	if assert.ErrorAs(t, err, &failuresErr) {
+		assert.ErrorIs(t, failuresErr.Failures[0].Err, context.DeadlineExceeded)
+		assert.Contains(t, failuresErr.Failures[0].Err.Error(), "task timed out after 100ms")
+	}
+
+	// the temp directory is removed before the timeout is reported
+	_, statErr := os.Stat(<-task.started)
+	assert.True(t, os.IsNotExist(statErr))
+}
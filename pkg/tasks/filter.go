@@ -0,0 +1,112 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/platformutil"
+	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/util"
+	"github.com/rs/zerolog/log"
+)
+
+// SelectTaskName is the task name of failures that occur while selecting repositories
+const SelectTaskName = "select"
+
+// Filter selects the repositories that are processed, empty fields match all repositories
+type Filter struct {
+	Repositories []string   // Repositories are full names in the form namespace/name
+	Namespaces   []string   // Namespaces are organizations, groups or users
+	Patterns     []string   // Patterns are globs matched against namespace/name, e.g. primelib/*-java
+	Topics       []string   // Topics match repositories that have at least one of the topics
+	Since        *time.Time // Since selects repositories whose config or upstream spec changed after the time
+}
+
+// Match reports whether the repository matches the name, namespace, pattern and topic filters
+func (f Filter) Match(repo api.Repository) bool {
+	name := repo.Namespace + "/" + repo.Name
+	if len(f.Repositories) > 0 && !slices.ContainsFunc(f.Repositories, func(r string) bool { return strings.EqualFold(r, name) }) {
+		return false
+	}
+	if len(f.Namespaces) > 0 && !slices.ContainsFunc(f.Namespaces, func(n string) bool { return strings.EqualFold(n, repo.Namespace) }) {
+		return false
+	}
+	if len(f.Patterns) > 0 && !slices.ContainsFunc(f.Patterns, func(p string) bool {
+		matched, err := path.Match(p, name)
+		return err == nil && matched
+	}) {
+		return false
+	}
+	if len(f.Topics) > 0 && !slices.ContainsFunc(f.Topics, func(t string) bool { return slices.Contains(repo.Topics, t) }) {
+		return false
+	}
+
+	return true
+}
+
+// Changed reports whether the config or an upstream source of the repository changed since the filter time, changes that can not be detected count as changed
+func (f Filter) Changed(ctx context.Context, repo api.Repository, conf config.Configuration) bool {
+	if f.Since == nil {
+		return true
+	}
+
+	configUpdated, err := platformutil.LastCommitDate(repo, config.ConfigFileName)
+	if err != nil || configUpdated == nil || configUpdated.After(*f.Since) {
+		return true
+	}
+	for _, source := range conf.Spec.Sources {
+		if source.URL == "" {
+			continue
+		}
+		fetchCtx, cancel := util.WithTimeout(ctx, primelib.DefaultFetchTimeout)
+		modified, err := primelib.SourceModifiedSince(fetchCtx, source, *f.Since)
+		cancel()
+		if err != nil {
+			log.Debug().Err(err).Str("url", source.URL).Msg("failed to check spec source for changes")
+		}
+		if modified {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SelectRepositories returns the repositories that match the filter, repositories without a config are skipped.
+// Repositories whose config can not be read for another reason are returned as failures.
+func SelectRepositories(ctx context.Context, platform api.Platform, repos []api.Repository, filter Filter) ([]api.Repository, []Failure) {
+	var selected []api.Repository
+	var failures []Failure
+	for _, repo := range repos {
+		name := repo.Namespace + "/" + repo.Name
+		if !filter.Match(repo) {
+			continue
+		}
+
+		content, err := platform.FileContent(repo, repo.DefaultBranch, config.ConfigFileName)
+		if platformutil.IsNotFound(err) {
+			log.Debug().Str("repository", name).Msg("no " + config.ConfigFileName + " found, skipping repository")
+			continue
+		} else if err != nil {
+			log.Warn().Err(err).Str("repository", name).Msg("failed to get " + config.ConfigFileName + ", skipping repository")
+			failures = append(failures, Failure{Repository: name, Task: SelectTaskName, Err: fmt.Errorf("failed to get %s: %w", config.ConfigFileName, err)})
+			continue
+		}
+
+		// invalid configs are selected, so the task reports the error
+		if conf, err := config.FromString(content); err == nil && !filter.Changed(ctx, repo, conf) {
+			log.Debug().Str("repository", name).Time("since", *filter.Since).Msg("config and upstream unchanged, skipping repository")
+			continue
+		}
+
+		selected = append(selected, repo)
+	}
+
+	return selected, failures
+}
@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v69/github"
+	"github.com/stretchr/testify/assert"
+)
+
+// configPlatform serves primelib.yaml for the repositories in configs
+type configPlatform struct {
+	api.Platform
+	configs map[string]string
+}
+
+func (p configPlatform) FileContent(repository api.Repository, branch string, path string) (string, error) {
+	if repository.Name == "unavailable" {
+		return "", errors.New("failed to get file content: 401 Bad credentials")
+	}
+	content, ok := p.configs[repository.Namespace+"/"+repository.Name]
+	if !ok {
+		return "", &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	}
+	return content, nil
+}
+
+func TestFilterMatch(t *testing.T) {
+	repo := api.Repository{Namespace: "primelib", Name: "example-java", Topics: []string{"sdk", "java"}}
+
+	assert.True(t, Filter{}.Match(repo))
+	assert.True(t, Filter{Repositories: []string{"PrimeLib/Example-Java"}}.Match(repo))
+	assert.False(t, Filter{Repositories: []string{"primelib/example-go"}}.Match(repo))
+	assert.True(t, Filter{Namespaces: []string{"other", "primelib"}}.Match(repo))
+	assert.False(t, Filter{Namespaces: []string{"other"}}.Match(repo))
+	assert.True(t, Filter{Patterns: []string{"primelib/*-java"}}.Match(repo))
+	assert.False(t, Filter{Patterns: []string{"*/*-go"}}.Match(repo))
+	assert.True(t, Filter{Topics: []string{"python", "java"}}.Match(repo))
+	assert.False(t, Filter{Topics: []string{"python"}}.Match(repo))
+	assert.False(t, Filter{Namespaces: []string{"primelib"}, Topics: []string{"python"}}.Match(repo))
+}
+
+func TestSelectRepositories(t *testing.T) {
+	platform := configPlatform{configs: map[string]string{
+		"primelib/example": "name: example\n",
+		"primelib/invalid": "name: [\n",
+	}}
+	repos := []api.Repository{
+		{Namespace: "primelib", Name: "example"},
+		{Namespace: "primelib", Name: "no-config"},
+		{Namespace: "primelib", Name: "invalid"},
+		{Namespace: "primelib", Name: "unavailable"},
+		{Namespace: "other", Name: "example"},
+	}
+
+	selected, failures := SelectRepositories(context.Background(), platform, repos, Filter{Namespaces: []string{"primelib"}})
+	assert.Equal(t, []api.Repository{repos[0], repos[2]}, selected)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, "primelib/unavailable", failures[0].Repository)
+	assert.ErrorContains(t, failures[0].Err, "401 Bad credentials")
+}
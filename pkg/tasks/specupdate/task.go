@@ -1,6 +1,7 @@
 package codegeneration
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"os"
@@ -12,17 +13,27 @@ import (
 	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
 	cp "github.com/otiai10/copy"
 	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/failure"
+	"github.com/primelib/primecodegen-app/pkg/metrics"
+	"github.com/primelib/primecodegen-app/pkg/platformutil"
 	"github.com/primelib/primecodegen-app/pkg/primelib"
+	"github.com/primelib/primecodegen-app/pkg/report"
 	"github.com/primelib/primecodegen-app/pkg/specutil"
+	"github.com/primelib/primecodegen-app/pkg/tracing"
+	"github.com/primelib/primecodegen-app/pkg/util"
 	"github.com/rs/zerolog/log"
 )
 
-const branchName = "feat/primelib-spec"
+// BranchName is the branch of the spec update pull request
+const BranchName = "feat/primelib-spec"
 
 //go:embed templates/description.gohtml
 var descriptionTemplate []byte
 
-type SpecUpdateTask struct{}
+type SpecUpdateTask struct {
+	Report   *report.Report    // Report collects the outcome of each repository, optional
+	Timeouts primelib.Timeouts // Timeouts limits the external processes
+}
 
 // Name returns the name of the task
 func (n SpecUpdateTask) Name() string {
@@ -31,28 +42,51 @@ func (n SpecUpdateTask) Name() string {
 
 // Execute runs the task
 func (n SpecUpdateTask) Execute(ctx taskcommon.TaskContext) error {
+	return n.ExecuteContext(context.Background(), ctx)
+}
+
+// ExecuteContext runs the task, external processes are killed and the task stops once parent is done
+func (n SpecUpdateTask) ExecuteContext(parent context.Context, ctx taskcommon.TaskContext) error {
+	spanCtx, span := tracing.Start(parent, "task "+n.Name(), tracing.Repository(ctx.Repository), tracing.TaskKey.String(n.Name()))
+	phases := tracing.NewPhases(spanCtx)
+	err := n.execute(ctx, phases)
+	phases.End(err)
+	tracing.End(span, err)
+
+	return err
+}
+
+func (n SpecUpdateTask) execute(ctx taskcommon.TaskContext, phases *tracing.Phases) error {
+	logger := log.With().Str("repository", ctx.Repository.Namespace+"/"+ctx.Repository.Name).Str("task", n.Name()).Logger()
+	phases.Start("load config")
 	content, err := ctx.Platform.FileContent(ctx.Repository, ctx.Repository.DefaultBranch, config.ConfigFileName)
 	if err != nil {
-		return fmt.Errorf("failed to get %s content: %w", config.ConfigFileName, err)
+		return failure.Step("load config", fmt.Errorf("failed to get %s content: %w", config.ConfigFileName, err))
 	}
 
 	// load config
 	conf, err := config.FromString(content)
 	if err != nil {
-		return fmt.Errorf("failed to load %s: %w", config.ConfigFileName, err)
+		return failure.Step("load config", fmt.Errorf("failed to load %s: %w", config.ConfigFileName, err))
 	}
 
+	entry := n.Report.Repository(ctx.Repository.Namespace+"/"+ctx.Repository.Name, n.Name())
+
 	// create helper
 	helper := simpletask.New(ctx)
 
 	// clone repository
+	cloneCtx := phases.Start("clone")
 	err = helper.Clone()
 	if err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+		return failure.Step("clone", fmt.Errorf("failed to clone repository: %w", err))
+	}
+	if err = cloneCtx.Err(); err != nil {
+		return err
 	}
 
 	// create and checkout new branch
-	branch := branchName
+	branch := BranchName
 	err = helper.CreateBranch(branch)
 	if err != nil {
 		return fmt.Errorf("failed to create branch: %w", err)
@@ -68,16 +102,22 @@ func (n SpecUpdateTask) Execute(ctx taskcommon.TaskContext) error {
 	defer os.Remove(originalSpecFile.Name())
 
 	// update spec
-	err = primelib.Update(ctx.Directory, conf, ctx.Repository)
+	updateCtx := phases.Start("update")
+	updateResult, err := primelib.Update(updateCtx, ctx.Directory, conf, ctx.Repository, primelib.UpdateOptions{Timeouts: n.Timeouts})
+	entry.AddUpdate(updateResult)
 	if err != nil {
-		return fmt.Errorf("failed to generate: %w", err)
+		return failure.Step("update spec", fmt.Errorf("failed to generate: %w", err))
 	}
 
 	// store updated spec file
-	diff, err := specutil.DiffSpec("openapi", originalSpecFile.Name(), specFile)
+	diffCtx, cancel := util.WithTimeout(phases.Start("diff"), n.Timeouts.Diff)
+	diff, err := specutil.DiffSpec(diffCtx, "openapi", originalSpecFile.Name(), specFile)
+	cancel()
 	if err != nil {
-		log.Warn().Err(err).Msg("failed to diff spec file")
+		logger.Warn().Err(err).Msg("failed to diff spec file")
 	}
+	entry.SetDiff(diff)
+	metrics.BreakingChanges(diff.BreakingChanges())
 	if len(diff.OpenAPI) > 15 {
 		diff.OpenAPI = diff.OpenAPI[:15] // limit to the first n changes, sorted by level
 	}
@@ -87,12 +127,19 @@ func (n SpecUpdateTask) Execute(ctx taskcommon.TaskContext) error {
 	if err != nil {
 		return fmt.Errorf("failed to get uncommitted changes: %w", err)
 	}
+	entry.SetChangedFiles(ctx.Directory, changes)
 	commitMessage := "feat: update openapi spec"
 	description, err := vcsapp.Render(string(descriptionTemplate), map[string]interface{}{
 		"PlatformName": ctx.Platform.Name(),
 		"PlatformSlug": ctx.Platform.Slug(),
 		"Name":         conf.Name,
 		"SpecDiff":     diff,
+		"Conflicts":    updateResult.MergeConflicts,
+		"Unmatched":    updateResult.Unmatched,
+		"Losses":       updateResult.ConversionLosses,
+		"Lint":         updateResult.Lint,
+		"Renames":      updateResult.OperationIdRenames,
+		"Schemas":      updateResult.SchemaRenames,
 		"Footer":       os.Getenv("PRIMEAPP_FOOTER_HIDE") != "true",
 		"FooterCustom": os.Getenv("PRIMEAPP_FOOTER_CUSTOM"),
 	})
@@ -102,19 +149,36 @@ func (n SpecUpdateTask) Execute(ctx taskcommon.TaskContext) error {
 
 	// do not commit if only .openapi-generator/FILES changed
 	if len(changes) == 0 {
-		log.Info().Int("total-changes", len(changes)).Msg("no changes detected, skipping commit and merge request")
+		logger.Info().Int("total-changes", len(changes)).Msg("no changes detected, skipping commit and merge request")
+		if entry != nil {
+			entry.Status = report.StatusUnchanged
+		}
+		metrics.MergeRequest(metrics.MergeRequestSkipped)
 		return nil
 	}
 
 	// commit push and create or update merge request
+	if err = phases.Start("push").Err(); err != nil {
+		return err
+	}
+	mergeRequestAction := metrics.MergeRequestOpened
+	if exists, err := platformutil.HasOpenMergeRequest(ctx.Platform, ctx.Repository, branch); err != nil {
+		logger.Warn().Err(err).Msg("failed to check for an open merge request")
+	} else if exists {
+		mergeRequestAction = metrics.MergeRequestUpdated
+	}
 	err = helper.CommitPushAndMergeRequest(commitMessage, commitMessage, string(description), "")
 	if err != nil {
-		return fmt.Errorf("failed to commit push and create or update merge request: %w", err)
+		return failure.Step("create merge request", fmt.Errorf("failed to commit push and create or update merge request: %w", err))
+	}
+	metrics.MergeRequest(mergeRequestAction)
+	if entry != nil {
+		entry.MergeRequest = &report.MergeRequest{Branch: branch, Title: commitMessage}
 	}
 
 	return nil
 }
 
-func NewTask() SpecUpdateTask {
-	return SpecUpdateTask{}
+func NewTask(rep *report.Report, timeouts primelib.Timeouts) SpecUpdateTask {
+	return SpecUpdateTask{Report: rep, Timeouts: timeouts}
 }
@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName  = "github.com/primelib/primecodegen-app"
+	serviceName = "primelib-app"
+)
+
+// Span attribute keys
+const (
+	RepositoryKey = attribute.Key("primelib.repository")
+	TaskKey       = attribute.Key("primelib.task")
+	GeneratorKey  = attribute.Key("primelib.generator")
+	SourceKey     = attribute.Key("primelib.source")
+)
+
+// Repository returns the repository attribute, empty for local runs
+func Repository(repo api.Repository) attribute.KeyValue {
+	if repo.Name == "" {
+		return RepositoryKey.String("")
+	}
+	return RepositoryKey.String(repo.Namespace + "/" + repo.Name)
+}
+
+// Setup configures the global tracer provider from the environment and returns a function that flushes and stops it.
+// OTEL_TRACES_EXPORTER selects otlp or console, the OTLP exporter is also enabled by OTEL_EXPORTER_OTLP_ENDPOINT, tracing is disabled otherwise.
+func Setup(ctx context.Context, version string) (func(context.Context) error, error) {
+	exporter, err := exporterFromEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	return install(ctx, version, sdktrace.WithBatcher(exporter))
+}
+
+// SetupWriter writes all spans synchronously as JSON to the writer, used in tests
+func SetupWriter(w io.Writer) (func(context.Context) error, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout trace exporter: %w", err)
+	}
+
+	return install(context.Background(), "", sdktrace.WithSyncer(exporter))
+}
+
+func install(ctx context.Context, version string, processor sdktrace.TracerProviderOption) (func(context.Context) error, error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName), attribute.String("service.version", version)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(processor, sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// exporterFromEnv returns the span exporter selected by the environment, nil if tracing is disabled
+func exporterFromEnv(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")) {
+	case "otlp":
+		return otlptracehttp.New(ctx)
+	case "console":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case "none":
+		return nil, nil
+	case "":
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			return otlptracehttp.New(ctx)
+		}
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q, expected otlp, console or none", os.Getenv("OTEL_TRACES_EXPORTER"))
+	}
+}
+
+// Start starts a span, the global tracer provider is a no-op until Setup enabled an exporter
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error on the span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Phases traces consecutive phases of a function, starting a phase ends the previous one
+type Phases struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+// NewPhases creates phases whose spans are children of the span in ctx
+func NewPhases(ctx context.Context) *Phases {
+	return &Phases{ctx: ctx}
+}
+
+// Start ends the current phase and starts the next one, the returned context carries the span of the new phase
+func (p *Phases) Start(name string, attrs ...attribute.KeyValue) context.Context {
+	p.End(nil)
+	ctx, span := Start(p.ctx, name, attrs...)
+	p.span = span
+	return ctx
+}
+
+// End ends the current phase, the error is recorded on it
+func (p *Phases) End(err error) {
+	if p.span != nil {
+		End(p.span, err)
+		p.span = nil
+	}
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSetupDisabledByDefault(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	shutdown, err := Setup(context.Background(), "test")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, span := Start(context.Background(), "update")
+	assert.False(t, span.SpanContext().IsValid())
+	span.End()
+}
+
+func TestSetupUnsupportedExporter(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "zipkin")
+
+	_, err := Setup(context.Background(), "test")
+	assert.ErrorContains(t, err, "unsupported OTEL_TRACES_EXPORTER")
+}
+
+func TestPhases(t *testing.T) {
+	var buf bytes.Buffer
+	shutdown, err := SetupWriter(&buf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	ctx, span := Start(context.Background(), "task generate", Repository(api.Repository{Namespace: "primelib", Name: "example-java"}), TaskKey.String("generate"))
+	phases := NewPhases(ctx)
+	phases.Start("clone")
+	phases.Start("generate", GeneratorKey.String("java-httpclient"))
+	err = errors.New("generator failed")
+	phases.End(err)
+	End(span, err)
+
+	out := buf.String()
+	assert.Contains(t, out, `"Name":"clone"`)
+	assert.Contains(t, out, `"Name":"generate"`)
+	assert.Contains(t, out, `"Name":"task generate"`)
+	assert.Contains(t, out, `"Value":"primelib/example-java"`)
+	assert.Contains(t, out, `"Value":"java-httpclient"`)
+	assert.Contains(t, out, `"Description":"generator failed"`)
+	assert.Equal(t, 3, bytes.Count(buf.Bytes(), []byte(`"SpanContext"`)))
+}
+
+func TestRepositoryLocal(t *testing.T) {
+	assert.Equal(t, "", Repository(api.Repository{}).Value.AsString())
+}
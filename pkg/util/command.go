@@ -0,0 +1,26 @@
+package util
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// commandWaitDelay is the time to wait for the output pipes after a cancelled process was killed
+const commandWaitDelay = 10 * time.Second
+
+// Command creates a command that runs in its own process group, the whole group is killed once the context is done
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.WaitDelay = commandWaitDelay
+	return cmd
+}
+
+// WithTimeout limits the context to the timeout, a timeout of 0 only adds a cancel function
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
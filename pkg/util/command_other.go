@@ -0,0 +1,10 @@
+//go:build !unix
+
+package util
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is not supported, cancellation only kills the process itself
+func setProcessGroup(cmd *exec.Cmd) {}
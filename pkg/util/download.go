@@ -1,17 +1,28 @@
 package util
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
-func DownloadString(url string) ([]byte, error) {
+// DownloadString downloads the resource, the request is aborted once ctx is done
+func DownloadString(ctx context.Context, url string) ([]byte, error) {
 	// Send GET request
-	response, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("unexpected status code %d for %s", response.StatusCode, url)
+	}
 
 	// return response body as string
 	body, err := io.ReadAll(response.Body)
@@ -20,3 +31,60 @@ func DownloadString(url string) ([]byte, error) {
 	}
 	return body, nil
 }
+
+// ModifiedSince sends a conditional HEAD request and reports whether the resource changed after since, resources without Last-Modified support are reported as modified
+func ModifiedSince(ctx context.Context, url string, since time.Time) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return true, err
+	}
+	req.Header.Set("If-Modified-Since", since.UTC().Format(http.TimeFormat))
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if lastModified, err := http.ParseTime(response.Header.Get("Last-Modified")); err == nil {
+		return lastModified.After(since), nil
+	}
+
+	return true, nil
+}
+
+// ConditionalGet downloads the resource unless it matches the etag or was not modified since lastModified, the body is nil if the resource is unchanged
+func ConditionalGet(ctx context.Context, url string, etag string, lastModified string) ([]byte, string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified {
+		return nil, etag, lastModified, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, "", "", fmt.Errorf("unexpected status code %d for %s", response.StatusCode, url)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return body, response.Header.Get("ETag"), response.Header.Get("Last-Modified"), nil
+}
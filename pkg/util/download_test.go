@@ -0,0 +1,74 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifiedSince(t *testing.T) {
+	lastModified := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conditional":
+			http.ServeContent(w, r, "openapi.yaml", lastModified, nil)
+		case "/header":
+			w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		}
+	}))
+	defer server.Close()
+
+	modified, err := ModifiedSince(context.Background(), server.URL+"/conditional", lastModified.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, modified)
+
+	modified, err = ModifiedSince(context.Background(), server.URL+"/header", lastModified.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, modified)
+
+	modified, err = ModifiedSince(context.Background(), server.URL+"/unknown", lastModified)
+	require.NoError(t, err)
+	assert.True(t, modified)
+}
+
+func TestConditionalGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("openapi: 3.0.3\n"))
+	}))
+	defer server.Close()
+
+	body, etag, _, err := ConditionalGet(context.Background(), server.URL, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "openapi: 3.0.3\n", string(body))
+	assert.Equal(t, `"v1"`, etag)
+
+	body, etag, _, err = ConditionalGet(context.Background(), server.URL, etag, "")
+	require.NoError(t, err)
+	assert.Nil(t, body)
+	assert.Equal(t, `"v1"`, etag)
+
+	_, _, _, err = ConditionalGet(context.Background(), server.URL+"/missing\x7f", "", "")
+	assert.Error(t, err)
+}
+
+func TestDownloadStringCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := DownloadString(ctx, server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -2,6 +2,8 @@ package util
 
 import (
 	"regexp"
+	"strings"
+	"unicode"
 )
 
 const ansi = "[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))"
@@ -12,3 +14,62 @@ var stripRegex = regexp.MustCompile(ansi)
 func StripANSI(str string) string {
 	return stripRegex.ReplaceAllString(str, "")
 }
+
+// SplitWords splits a string into words at separators, lower to upper case transitions and the end of acronyms.
+func SplitWords(str string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(str)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		}
+
+		if len(current) > 0 && unicode.IsUpper(r) {
+			prev := current[len(current)-1]
+			acronymEnd := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || acronymEnd {
+				words = append(words, string(current))
+				current = nil
+			}
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+
+	return words
+}
+
+// ToCamelCase converts a string to lower camel case, e.g. list_pets and ListPets become listPets.
+func ToCamelCase(str string) string {
+	var sb strings.Builder
+	for i, word := range SplitWords(str) {
+		word = strings.ToLower(word)
+		if i > 0 {
+			r := []rune(word)
+			r[0] = unicode.ToUpper(r[0])
+			word = string(r)
+		}
+		sb.WriteString(word)
+	}
+
+	return sb.String()
+}
+
+// ToPascalCase converts a string to upper camel case, e.g. list_pets becomes ListPets.
+func ToPascalCase(str string) string {
+	var sb strings.Builder
+	for _, word := range SplitWords(str) {
+		r := []rune(strings.ToLower(word))
+		r[0] = unicode.ToUpper(r[0])
+		sb.WriteString(string(r))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToCamelCase(t *testing.T) {
+	assert.Equal(t, "listPets", ToCamelCase("list_pets"))
+	assert.Equal(t, "listPets", ToCamelCase("ListPets"))
+	assert.Equal(t, "getHttpServer", ToCamelCase("getHTTPServer"))
+	assert.Equal(t, "getV1Pets", ToCamelCase("get-v1-pets"))
+	assert.Equal(t, "getVulnById", ToCamelCase("GetVulnByID"))
+}
+
+func TestToPascalCase(t *testing.T) {
+	assert.Equal(t, "ListPets", ToPascalCase("list_pets"))
+	assert.Equal(t, "FooResponseDto", ToPascalCase("FooResponseDTO"))
+}
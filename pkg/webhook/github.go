@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// trustedAssociations are the comment authors that may request a regeneration
+var trustedAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// parseGitHub returns the jobs requested by a GitHub webhook event, unrelated events return no jobs
+func parseGitHub(eventType string, payload []byte) ([]Job, error) {
+	if !slices.Contains([]string{"push", "pull_request", "issue_comment"}, eventType) {
+		return nil, nil
+	}
+	event, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse github %s event: %w", eventType, err)
+	}
+
+	switch e := event.(type) {
+	case *github.PushEvent:
+		repo := e.GetRepo()
+		if e.GetRef() != "refs/heads/"+repo.GetDefaultBranch() {
+			return nil, nil
+		}
+		var files []string
+		for _, c := range e.Commits {
+			files = append(files, c.Added...)
+			files = append(files, c.Modified...)
+			files = append(files, c.Removed...)
+		}
+		return pushJobs(repo.GetFullName(), files, fmt.Sprintf("push %s", e.GetAfter())), nil
+	case *github.PullRequestEvent:
+		pr := e.GetPullRequest()
+		if e.GetAction() != "closed" || !pr.GetMerged() || !isGenerationBranch(pr.GetHead().GetRef()) {
+			return nil, nil
+		}
+		return []Job{{Repository: e.GetRepo().GetFullName(), Kind: KindRelease, Force: true, Reasons: []string{fmt.Sprintf("pull request #%d merged", pr.GetNumber())}}}, nil
+	case *github.IssueCommentEvent:
+		comment := e.GetComment()
+		if e.GetAction() != "created" || !e.GetIssue().IsPullRequest() || !isRegenerateCommand(comment.GetBody()) {
+			return nil, nil
+		}
+		if !slices.Contains(trustedAssociations, comment.GetAuthorAssociation()) {
+			return nil, fmt.Errorf("%s requested a regeneration without write access", comment.GetUser().GetLogin())
+		}
+		return []Job{{Repository: e.GetRepo().GetFullName(), Kind: KindGenerate, Force: true, Reasons: []string{fmt.Sprintf("%s on pull request #%d", regenerateCommand, e.GetIssue().GetNumber())}}}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// GitLabAccess returns the access level of a user in a project
+type GitLabAccess func(projectID int, userID int) (gitlab.AccessLevelValue, error)
+
+// GitLabMemberAccess looks up the access level with the members API, inherited group memberships are included and non-members have no access
+func GitLabMemberAccess(client *gitlab.Client) GitLabAccess {
+	return func(projectID int, userID int) (gitlab.AccessLevelValue, error) {
+		member, resp, err := client.ProjectMembers.GetInheritedProjectMember(projectID, userID)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return gitlab.NoPermissions, nil
+		}
+		if err != nil {
+			return gitlab.NoPermissions, fmt.Errorf("failed to get project member: %w", err)
+		}
+		return member.AccessLevel, nil
+	}
+}
+
+// parseGitLab returns the jobs requested by a GitLab webhook event, unrelated events return no jobs.
+// Regeneration requests are only accepted from users with at least developer access, which is looked up with access.
+func parseGitLab(eventType string, payload []byte, access GitLabAccess) ([]Job, error) {
+	if !slices.Contains([]gitlab.EventType{gitlab.EventTypePush, gitlab.EventTypeMergeRequest, gitlab.EventTypeNote}, gitlab.EventType(eventType)) {
+		return nil, nil
+	}
+	event, err := gitlab.ParseWebhook(gitlab.EventType(eventType), payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gitlab %s event: %w", eventType, err)
+	}
+
+	switch e := event.(type) {
+	case *gitlab.PushEvent:
+		if e.Ref != "refs/heads/"+e.Project.DefaultBranch {
+			return nil, nil
+		}
+		var files []string
+		for _, c := range e.Commits {
+			files = append(files, c.Added...)
+			files = append(files, c.Modified...)
+			files = append(files, c.Removed...)
+		}
+		return pushJobs(e.Project.PathWithNamespace, files, fmt.Sprintf("push %s", e.After)), nil
+	case *gitlab.MergeEvent:
+		if e.ObjectAttributes.Action != "merge" || !isGenerationBranch(e.ObjectAttributes.SourceBranch) {
+			return nil, nil
+		}
+		return []Job{{Repository: e.Project.PathWithNamespace, Kind: KindRelease, Force: true, Reasons: []string{fmt.Sprintf("merge request !%d merged", e.ObjectAttributes.IID)}}}, nil
+	case *gitlab.MergeCommentEvent:
+		// edited notes are ignored, so a regeneration is only requested once
+		if e.ObjectAttributes.Action != gitlab.CommentEventActionCreate || !isRegenerateCommand(e.ObjectAttributes.Note) {
+			return nil, nil
+		}
+		if access == nil {
+			return nil, errors.New("regeneration requests can not be verified without a gitlab api client")
+		}
+		level, err := access(e.ProjectID, e.ObjectAttributes.AuthorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify access of %s: %w", e.User.Username, err)
+		}
+		if level < gitlab.DeveloperPermissions {
+			return nil, fmt.Errorf("%s requested a regeneration without write access", e.User.Username)
+		}
+		return []Job{{Repository: e.Project.PathWithNamespace, Kind: KindGenerate, Force: true, Reasons: []string{fmt.Sprintf("%s on merge request !%d", regenerateCommand, e.MergeRequest.IID)}}}, nil
+	}
+
+	return nil, nil
+}
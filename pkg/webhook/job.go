@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/tasks/codegeneration"
+)
+
+// regenerateCommand is the pull request comment that requests a generation run
+const regenerateCommand = "/primelib regenerate"
+
+// Kind is the task a job runs
+type Kind string
+
+const (
+	KindGenerate Kind = "generate"
+	KindUpdate   Kind = "update"
+	KindRelease  Kind = "release"
+)
+
+// Job runs a task for a single repository, requested by one or more webhook events
+type Job struct {
+	Repository string   // Repository in the form namespace/name
+	Kind       Kind     // Kind is the task that is executed
+	Reasons    []string // Reasons are the events that requested the job
+	Files      []string // Files changed on the default branch, a generate job only runs if one of them is a patch of the config
+	Force      bool     // Force runs the job regardless of the changed files
+}
+
+// Key identifies jobs that are deduplicated
+func (j Job) Key() string {
+	return string(j.Kind) + ":" + strings.ToLower(j.Repository)
+}
+
+// merge combines two jobs with the same key
+func (j Job) merge(other Job) Job {
+	j.Reasons = append(slices.Clone(j.Reasons), other.Reasons...)
+	j.Force = j.Force || other.Force
+	for _, f := range other.Files {
+		if !slices.Contains(j.Files, f) {
+			j.Files = append(j.Files, f)
+		}
+	}
+
+	return j
+}
+
+// pushJobs returns a generate job if the config or a file that may be a patch changed on the default branch
+func pushJobs(repository string, files []string, reason string) []Job {
+	job := Job{Repository: repository, Kind: KindGenerate, Reasons: []string{reason}}
+	for _, f := range files {
+		switch {
+		case f == config.ConfigFileName:
+			job.Force = true
+		case slices.Contains([]string{".yaml", ".yml", ".json"}, path.Ext(f)) && !slices.Contains(job.Files, f):
+			job.Files = append(job.Files, f)
+		}
+	}
+	if !job.Force && len(job.Files) == 0 {
+		return nil
+	}
+
+	return []Job{job}
+}
+
+// isGenerationBranch reports whether the branch belongs to a generation pull request
+func isGenerationBranch(branch string) bool {
+	return branch == codegeneration.BranchName
+}
+
+// isRegenerateCommand reports whether the comment starts with the regenerate command
+func isRegenerateCommand(comment string) bool {
+	fields := strings.Fields(comment)
+	return len(fields) >= 2 && fields[0]+" "+fields[1] == regenerateCommand
+}
+
+// PatchChanged reports whether one of the files is a patch file referenced by the config
+func PatchChanged(conf config.Configuration, files []string) bool {
+	for _, p := range append(slices.Clone(conf.Spec.InputPatches), conf.Spec.Patches...) {
+		if p.File != "" && slices.Contains(files, path.Clean(p.File)) {
+			return true
+		}
+	}
+
+	return false
+}
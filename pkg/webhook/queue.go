@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrQueueFull is returned if the queue can not accept more jobs
+var ErrQueueFull = errors.New("job queue is full")
+
+// Queue runs jobs in the background, jobs for the same repository and kind are merged until they start and never run in parallel
+type Queue struct {
+	mu      sync.Mutex
+	keys    chan string
+	pending map[string]Job
+	locks   map[string]*sync.Mutex
+	closed  bool
+	wg      sync.WaitGroup
+	run     func(context.Context, Job) error
+}
+
+// NewQueue creates a queue that holds up to size jobs
+func NewQueue(size int, run func(context.Context, Job) error) *Queue {
+	return &Queue{
+		keys:    make(chan string, size),
+		pending: map[string]Job{},
+		locks:   map[string]*sync.Mutex{},
+		run:     run,
+	}
+}
+
+// Enqueue adds the job, returns false if it was merged into a job that did not start yet
+func (q *Queue) Enqueue(job Job) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false, errors.New("job queue is closed")
+	}
+
+	key := job.Key()
+	if pending, ok := q.pending[key]; ok {
+		q.pending[key] = pending.merge(job)
+		return false, nil
+	}
+	select {
+	case q.keys <- key:
+		q.pending[key] = job
+		return true, nil
+	default:
+		return false, ErrQueueFull
+	}
+}
+
+// Start starts the workers, once ctx is done running jobs are cancelled and queued jobs are dropped
+func (q *Queue) Start(ctx context.Context, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for key := range q.keys {
+				q.process(ctx, key)
+			}
+		}()
+	}
+}
+
+// Close stops accepting jobs and waits until the queued jobs finished
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.keys)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+// process runs the job, the repository lock prevents parallel jobs of different kinds for the same repository
+func (q *Queue) process(ctx context.Context, key string) {
+	q.mu.Lock()
+	job := q.pending[key]
+	delete(q.pending, key)
+	repoKey := strings.ToLower(job.Repository)
+	lock, ok := q.locks[repoKey]
+	if !ok {
+		lock = &sync.Mutex{}
+		q.locks[repoKey] = lock
+	}
+	q.mu.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+	logger := log.With().Str("repository", job.Repository).Str("job", string(job.Kind)).Logger()
+	if ctx.Err() != nil {
+		logger.Warn().Strs("reasons", job.Reasons).Msg("dropping webhook job, the server is shutting down")
+		return
+	}
+	logger.Info().Strs("reasons", job.Reasons).Msg("running webhook job")
+	if err := q.run(ctx, job); err != nil {
+		logger.Error().Err(err).Msg("webhook job failed")
+		return
+	}
+	logger.Info().Msg("webhook job finished")
+}
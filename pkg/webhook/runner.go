@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
+	"github.com/primelib/primecodegen-app/pkg/tasks/codegeneration"
+	"github.com/primelib/primecodegen-app/pkg/tasks/createtag"
+	specupdate "github.com/primelib/primecodegen-app/pkg/tasks/specupdate"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultRepositoryCacheTTL is how long the repository list is reused between webhook jobs
+const DefaultRepositoryCacheTTL = 5 * time.Minute
+
+// Runner executes webhook jobs on the platform
+type Runner struct {
+	Platform api.Platform
+	Options  tasks.ExecuteOptions // Options of the task execution, the filter limits the repositories that are processed
+	CacheTTL time.Duration        // CacheTTL is how long the repository list is reused between jobs, 0 lists the repositories for every job
+
+	mu       sync.Mutex
+	repos    []api.Repository
+	listedAt time.Time
+}
+
+// NewRunner creates a runner that reuses the repository list for DefaultRepositoryCacheTTL
+func NewRunner(platform api.Platform, opts tasks.ExecuteOptions) *Runner {
+	return &Runner{Platform: platform, Options: opts, CacheTTL: DefaultRepositoryCacheTTL}
+}
+
+// Run executes the task of the job, repositories that are not selected or whose change does not affect generation are skipped
+func (r *Runner) Run(ctx context.Context, job Job) error {
+	// release jobs tag the current commit, so they never use a cached commit hash
+	repo, found, err := r.findRepository(job.Repository, job.Kind == KindRelease)
+	if err != nil {
+		return err
+	}
+	var selected []api.Repository
+	if found {
+		var failures []tasks.Failure
+		selected, failures = tasks.SelectRepositories(ctx, r.Platform, []api.Repository{repo}, r.Options.Filter)
+		if len(failures) > 0 {
+			return failures[0].Err
+		}
+	}
+	if len(selected) == 0 {
+		log.Debug().Str("repository", job.Repository).Msg("repository is not accessible or not selected, skipping webhook job")
+		return nil
+	}
+
+	var task taskcommon.Task
+	switch job.Kind {
+	case KindGenerate:
+		if !job.Force {
+			content, err := r.Platform.FileContent(selected[0], selected[0].DefaultBranch, config.ConfigFileName)
+			if err != nil {
+				return fmt.Errorf("failed to get %s content: %w", config.ConfigFileName, err)
+			}
+			conf, err := config.FromString(content)
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %w", config.ConfigFileName, err)
+			}
+			if !PatchChanged(conf, job.Files) {
+				log.Debug().Str("repository", job.Repository).Strs("files", job.Files).Msg("no config or patch changed, skipping webhook job")
+				return nil
+			}
+		}
+		task = codegeneration.NewTask(nil, r.Options.Steps)
+	case KindUpdate:
+		task = specupdate.NewTask(nil, r.Options.Steps)
+	case KindRelease:
+		task = createtag.NewTask(nil)
+	default:
+		return fmt.Errorf("unsupported job kind %q", job.Kind)
+	}
+
+	return tasks.RunTasks(ctx, r.Platform, selected, []taskcommon.Task{task}, r.Options)
+}
+
+// findRepository returns the repository with the name from the cached repository list, the list is refreshed if it expired, fresh is set or the repository is missing
+func (r *Runner) findRepository(name string, fresh bool) (api.Repository, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cached := !fresh && r.repos != nil && time.Since(r.listedAt) < r.CacheTTL
+	if cached {
+		if i := indexRepository(r.repos, name); i >= 0 {
+			return r.repos[i], true, nil
+		}
+	}
+
+	repos, err := r.Platform.Repositories(api.RepositoryListOpts{
+		IncludeBranches:   true,
+		IncludeCommitHash: true,
+	})
+	if err != nil {
+		return api.Repository{}, false, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	r.repos = repos
+	r.listedAt = time.Now()
+	if i := indexRepository(repos, name); i >= 0 {
+		return repos[i], true, nil
+	}
+
+	return api.Repository{}, false, nil
+}
+
+// indexRepository returns the index of the repository with the case-insensitive name, -1 if it is missing
+func indexRepository(repos []api.Repository, name string) int {
+	return slices.IndexFunc(repos, func(repo api.Repository) bool { return strings.EqualFold(repo.Namespace+"/"+repo.Name, name) })
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/go-github/v69/github"
+	"github.com/rs/zerolog/log"
+)
+
+// maxPayloadSize limits the size of webhook payloads
+const maxPayloadSize = 25 << 20
+
+// Server receives GitHub and GitLab webhooks and queues the requested jobs, requests of a platform without a secret are rejected
+type Server struct {
+	GitHubSecret string       // GitHubSecret verifies the X-Hub-Signature-256 header
+	GitLabToken  string       // GitLabToken is compared with the X-Gitlab-Token header
+	GitLabAccess GitLabAccess // GitLabAccess verifies that GitLab comment authors have write access, regeneration requests are rejected if nil
+	Queue        *Queue
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize+1))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+	if len(payload) > maxPayloadSize {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var jobs []Job
+	switch {
+	case r.Header.Get("X-GitHub-Event") != "":
+		if s.GitHubSecret == "" || github.ValidateSignature(r.Header.Get(github.SHA256SignatureHeader), payload, []byte(s.GitHubSecret)) != nil {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		jobs, err = parseGitHub(r.Header.Get("X-GitHub-Event"), payload)
+	case r.Header.Get("X-Gitlab-Event") != "":
+		if s.GitLabToken == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(s.GitLabToken)) != 1 {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		jobs, err = parseGitLab(r.Header.Get("X-Gitlab-Event"), payload, s.GitLabAccess)
+	default:
+		http.Error(w, "unknown webhook", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("rejected webhook event")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, job := range jobs {
+		queued, err := s.Queue.Enqueue(job)
+		if errors.Is(err, ErrQueueFull) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Info().Str("repository", job.Repository).Str("job", string(job.Kind)).Strs("reasons", job.Reasons).Bool("merged", !queued).Msg("queued webhook job")
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
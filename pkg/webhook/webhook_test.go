@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v69/github"
+	"github.com/primelib/primecodegen-app/pkg/config"
+	"github.com/primelib/primecodegen-app/pkg/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func readPayload(t *testing.T, name string) []byte {
+	payload, err := os.ReadFile("testdata/" + name)
+	assert.NoError(t, err)
+	return payload
+}
+
+func TestParseGitHub(t *testing.T) {
+	jobs, err := parseGitHub("push", readPayload(t, "github-push.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Job{{Repository: "primelib/example-java", Kind: KindGenerate, Reasons: []string{"push 4b1e7d2c9a8f3e6d5c0b1a2f3e4d5c6b7a8f9e0d"}, Files: []string{".primelib/patches/pagination.json"}, Force: true}}, jobs)
+
+	jobs, err = parseGitHub("pull_request", readPayload(t, "github-pull-request-merged.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Job{{Repository: "primelib/example-java", Kind: KindRelease, Reasons: []string{"pull request #42 merged"}, Force: true}}, jobs)
+
+	jobs, err = parseGitHub("issue_comment", readPayload(t, "github-issue-comment.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Job{{Repository: "primelib/example-java", Kind: KindGenerate, Reasons: []string{"/primelib regenerate on pull request #42"}, Force: true}}, jobs)
+
+	// comments from users without write access are rejected
+	untrusted := bytes.Replace(readPayload(t, "github-issue-comment.json"), []byte(`"MEMBER"`), []byte(`"NONE"`), 1)
+	_, err = parseGitHub("issue_comment", untrusted)
+	assert.Error(t, err)
+
+	// pushes to other branches and unrelated events are ignored
+	jobs, err = parseGitHub("push", bytes.Replace(readPayload(t, "github-push.json"), []byte(`"refs/heads/main"`), []byte(`"refs/heads/feature"`), 1))
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+	jobs, err = parseGitHub("ping", []byte(`{"zen":"Keep it logically awesome."}`))
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+// gitlabMembers serves the access levels of project 15 on a fake GitLab members API
+func gitlabMembers(t *testing.T, levels map[string]int) GitLabAccess {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		level, ok := levels[strings.TrimPrefix(r.URL.Path, "/api/v4/projects/15/members/all/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"404 Not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id":1,"username":"user","access_level":%d}`, level)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL+"/api/v4"))
+	require.NoError(t, err)
+	return GitLabMemberAccess(client)
+}
+
+func TestParseGitLab(t *testing.T) {
+	access := gitlabMembers(t, map[string]int{"4": 30, "7": 10})
+	jobs, err := parseGitLab("Push Hook", readPayload(t, "gitlab-push.json"), access)
+	assert.NoError(t, err)
+	assert.Equal(t, []Job{{Repository: "primelib/example-go", Kind: KindGenerate, Reasons: []string{"push da1560886d4f094c3e6c9ef40349f7d38b5d27d7"}, Files: []string{"patches/pagination.json"}}}, jobs)
+
+	jobs, err = parseGitLab("Merge Request Hook", readPayload(t, "gitlab-merge-request-merged.json"), access)
+	assert.NoError(t, err)
+	assert.Equal(t, []Job{{Repository: "primelib/example-go", Kind: KindRelease, Reasons: []string{"merge request !7 merged"}, Force: true}}, jobs)
+
+	jobs, err = parseGitLab("Note Hook", readPayload(t, "gitlab-note.json"), access)
+	assert.NoError(t, err)
+	assert.Equal(t, []Job{{Repository: "primelib/example-go", Kind: KindGenerate, Reasons: []string{"/primelib regenerate on merge request !7"}, Force: true}}, jobs)
+
+	// guests and non-members can not request a regeneration
+	_, err = parseGitLab("Note Hook", readPayload(t, "gitlab-note-guest.json"), access)
+	assert.EqualError(t, err, "guest requested a regeneration without write access")
+	_, err = parseGitLab("Note Hook", readPayload(t, "gitlab-note-guest.json"), gitlabMembers(t, map[string]int{}))
+	assert.EqualError(t, err, "guest requested a regeneration without write access")
+	_, err = parseGitLab("Note Hook", readPayload(t, "gitlab-note.json"), nil)
+	assert.Error(t, err)
+
+	// edited notes do not request a regeneration again
+	edited := strings.Replace(string(readPayload(t, "gitlab-note.json")), `"action": "create"`, `"action": "update"`, 1)
+	jobs, err = parseGitLab("Note Hook", []byte(edited), access)
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+// listPlatform lists the repositories and counts the list calls, it has no config files
+type listPlatform struct {
+	api.Platform
+	mu    sync.Mutex
+	repos []api.Repository
+	lists int
+}
+
+func (p *listPlatform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lists++
+	return p.repos, nil
+}
+
+func (p *listPlatform) FileContent(repository api.Repository, branch string, path string) (string, error) {
+	return "", &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+}
+
+func TestRunnerRepositoryCache(t *testing.T) {
+	platform := &listPlatform{repos: []api.Repository{{Namespace: "primelib", Name: "example-go"}}}
+	runner := NewRunner(platform, tasks.ExecuteOptions{})
+
+	// jobs reuse the repository list
+	assert.NoError(t, runner.Run(context.Background(), Job{Repository: "primelib/example-go", Kind: KindUpdate}))
+	assert.NoError(t, runner.Run(context.Background(), Job{Repository: "PrimeLib/Example-Go", Kind: KindUpdate}))
+	assert.Equal(t, 1, platform.lists)
+
+	// missing repositories and release jobs refresh the list
+	platform.repos = append(platform.repos, api.Repository{Namespace: "primelib", Name: "example-java"})
+	assert.NoError(t, runner.Run(context.Background(), Job{Repository: "primelib/example-java", Kind: KindUpdate}))
+	assert.Equal(t, 2, platform.lists)
+	assert.NoError(t, runner.Run(context.Background(), Job{Repository: "primelib/example-go", Kind: KindRelease}))
+	assert.Equal(t, 3, platform.lists)
+
+	// without a cache every job lists the repositories
+	runner.CacheTTL = 0
+	assert.NoError(t, runner.Run(context.Background(), Job{Repository: "primelib/example-go", Kind: KindUpdate}))
+	assert.Equal(t, 4, platform.lists)
+}
+
+func TestPatchChanged(t *testing.T) {
+	conf := config.Configuration{Spec: config.Spec{
+		InputPatches: []config.SpecPatch{{File: "./patches/upstream.yaml"}},
+		Patches:      []config.SpecPatch{{Name: "simplifyEnums"}, {File: "patches/pagination.json"}},
+	}}
+	assert.True(t, PatchChanged(conf, []string{"patches/pagination.json"}))
+	assert.True(t, PatchChanged(conf, []string{"patches/upstream.yaml"}))
+	assert.False(t, PatchChanged(conf, []string{"openapi.yaml", "docs/usage.json"}))
+}
+
+func TestServer(t *testing.T) {
+	var mu sync.Mutex
+	var jobs []Job
+	queue := NewQueue(10, func(ctx context.Context, job Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		jobs = append(jobs, job)
+		return nil
+	})
+	server := httptest.NewServer(&Server{GitHubSecret: "secret", GitLabToken: "token", GitLabAccess: gitlabMembers(t, map[string]int{"4": 30}), Queue: queue})
+	defer server.Close()
+
+	send := func(headers map[string]string, payload []byte) int {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(payload))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	sign := func(payload []byte, secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	push := readPayload(t, "github-push.json")
+	assert.Equal(t, http.StatusUnauthorized, send(map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign(push, "wrong")}, push))
+	assert.Equal(t, http.StatusAccepted, send(map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign(push, "secret")}, push))
+	comment := readPayload(t, "github-issue-comment.json")
+	assert.Equal(t, http.StatusAccepted, send(map[string]string{"X-GitHub-Event": "issue_comment", "X-Hub-Signature-256": sign(comment, "secret")}, comment))
+
+	note := readPayload(t, "gitlab-note.json")
+	assert.Equal(t, http.StatusUnauthorized, send(map[string]string{"X-Gitlab-Event": "Note Hook", "X-Gitlab-Token": "wrong"}, note))
+	assert.Equal(t, http.StatusAccepted, send(map[string]string{"X-Gitlab-Event": "Note Hook", "X-Gitlab-Token": "token"}, note))
+	assert.Equal(t, http.StatusBadRequest, send(map[string]string{}, note))
+
+	// the push and the comment are merged into a single generate job
+	queue.Start(context.Background(), 1)
+	queue.Close()
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, "primelib/example-java", jobs[0].Repository)
+	assert.Equal(t, []string{"push 4b1e7d2c9a8f3e6d5c0b1a2f3e4d5c6b7a8f9e0d", "/primelib regenerate on pull request #42"}, jobs[0].Reasons)
+	assert.Equal(t, "primelib/example-go", jobs[1].Repository)
+}
+
+func TestQueueFull(t *testing.T) {
+	queue := NewQueue(1, func(ctx context.Context, job Job) error { return nil })
+	queued, err := queue.Enqueue(Job{Repository: "primelib/a", Kind: KindGenerate})
+	assert.NoError(t, err)
+	assert.True(t, queued)
+	queued, err = queue.Enqueue(Job{Repository: "primelib/a", Kind: KindGenerate})
+	assert.NoError(t, err)
+	assert.False(t, queued)
+	_, err = queue.Enqueue(Job{Repository: "primelib/b", Kind: KindGenerate})
+	assert.ErrorIs(t, err, ErrQueueFull)
+}